@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/czerwonk/dns-drain/pkg/cloudflare"
+	"github.com/czerwonk/dns-drain/pkg/provider"
+	"github.com/spf13/cobra"
+)
+
+type cloudflareCommand struct{}
+
+var cloudflareCmd = &cobra.Command{
+	Use:     "cloudflare",
+	Aliases: []string{"cf"},
+	Short:   "Drain and undrain DNS records using Cloudflare API",
+}
+
+func init() {
+	c := &cloudflareCommand{}
+
+	cloudflareCmd.PersistentFlags().String("api-token", "", "Cloudflare API token (if not set, CLOUDFLARE_API_TOKEN will be used)")
+	cloudflareCmd.PersistentFlags().String("endpoint", cloudflare.DefaultEndpoint, "Cloudflare API endpoint URL")
+	addDrainCommand(cloudflareCmd, c.provider)
+	addUndrainCommand(cloudflareCmd, c.provider)
+	addApplyCommand(cloudflareCmd, c.provider)
+	addStatusCommand(cloudflareCmd, c.provider)
+	addFindCommand(cloudflareCmd, c.provider)
+}
+
+func (c *cloudflareCommand) provider(cmd *cobra.Command) provider.Provider {
+	return cloudflare.NewProvider(cloudflareConfigFromArgs())
+}
+
+func cloudflareConfigFromArgs() cloudflare.Config {
+	token, _ := cloudflareCmd.PersistentFlags().GetString("api-token")
+	if token == "" {
+		token = os.Getenv("CLOUDFLARE_API_TOKEN")
+	}
+
+	if token == "" {
+		cobra.CheckErr(fmt.Errorf("please specify the Cloudflare API token"))
+	}
+
+	endpoint, _ := cloudflareCmd.PersistentFlags().GetString("endpoint")
+	return cloudflare.Config{
+		ApiToken: token,
+		Endpoint: endpoint,
+	}
+}
@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultTimeout     = 10 * time.Minute
+	defaultWaitTimeout = 5 * time.Minute
+	defaultParallelism = 10
+)
+
+// commandContext returns a context canceled on SIGINT/SIGTERM or when the timeout of the command is exceeded.
+// Changes already in progress are completed before the command returns. A second signal terminates the process immediately.
+func commandContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	timeout, _ := cmd.PersistentFlags().GetDuration("timeout")
+	cancelTimeout := context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-sigCh:
+			signal.Stop(sigCh)
+			log.Printf("Received %s. Waiting for changes in progress to complete (send again to force quit)...\n", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(sigCh)
+		cancelTimeout()
+		cancel()
+	}
+}
@@ -5,6 +5,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net"
@@ -14,45 +16,71 @@ import (
 
 	"github.com/czerwonk/dns-drain/pkg/changelog"
 	"github.com/czerwonk/dns-drain/pkg/drain"
+	"github.com/czerwonk/dns-drain/pkg/provider"
+	"github.com/czerwonk/dns-drain/pkg/result"
 )
 
-type DrainerFunc func(*cobra.Command, changelog.ChangeLogger, *drain.Options) drain.Drainer
+// ProviderFunc creates the provider from the flags of the command
+type ProviderFunc func(*cobra.Command) provider.Provider
 
-func addDrainCommand(cmd *cobra.Command, d DrainerFunc) {
+func addDrainCommand(cmd *cobra.Command, p ProviderFunc) {
 	drainCmd := &cobra.Command{
 		Use:   "drain",
 		Short: "Removes or replaces DNS records",
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			performDrainCommand(cmd, args, d)
+			performDrainCommand(cmd, args, p)
 		},
 	}
 	drainCmd.PersistentFlags().Bool("dry", false, "Do not modify DNS records (simulation only)")
-	drainCmd.PersistentFlags().StringP("file", "f", "drain.json", "Changelog file")
-	drainCmd.PersistentFlags().StringP("zone", "z", "", "Apply only to zones matching the specified regex")
-	drainCmd.PersistentFlags().String("skip", "", "Skip zones matching the specified regex")
-	drainCmd.PersistentFlags().StringP("type", "t", "", "Record type to change")
-	drainCmd.PersistentFlags().String("name", "", "Only change records with this name (regex)")
+	drainCmd.PersistentFlags().String("plan-out", "", "Write the changes to a plan file instead of applying them")
+	drainCmd.PersistentFlags().Bool("plan-overwrite", false, "Replace an existing plan file")
+	addChangeLogFlags(drainCmd)
+	addFilterFlags(drainCmd)
 	drainCmd.PersistentFlags().Int64("limit", -1, "Max number of records to change (-1 = unlimited)")
 	drainCmd.PersistentFlags().Bool("force", false, "Remove value from record even if it is the only value")
 	drainCmd.PersistentFlags().Bool("use-regex", false, "Regex to find data in DNS records to remove/replace")
 	drainCmd.PersistentFlags().String("replace-by", "", "Value to replace the matched data by (empty = no replacement)")
+	drainCmd.PersistentFlags().Bool("batch", false, "Apply all changes of a zone at once (atomically if supported by the provider)")
+	drainCmd.PersistentFlags().Int("parallelism", defaultParallelism, "Max number of zones processed concurrently (0 = unlimited)")
+	drainCmd.PersistentFlags().Bool("wait", false, "Wait until all changes are completed by the provider")
+	drainCmd.PersistentFlags().Duration("wait-timeout", defaultWaitTimeout, "Max duration to wait for changes to complete (0 = unlimited)")
+	drainCmd.PersistentFlags().Duration("timeout", defaultTimeout, "Max duration of the drain (0 = unlimited)")
 
 	cmd.AddCommand(drainCmd)
 }
 
-func performDrainCommand(cmd *cobra.Command, args []string, d DrainerFunc) {
-	f, _ := cmd.PersistentFlags().GetString("file")
-	if len(f) == 0 {
-		cobra.CheckErr(fmt.Errorf("please provide a path for the changelog"))
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringP("zone", "z", "", "Apply only to zones matching the specified regex")
+	cmd.PersistentFlags().String("skip", "", "Skip zones matching the specified regex")
+	cmd.PersistentFlags().StringP("type", "t", "", "Apply only to records of this type")
+	cmd.PersistentFlags().String("name", "", "Apply only to records with this name (regex)")
+}
+
+func addChangeLogFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringP("file", "f", "drain.json", "Changelog file")
+	addChangeLogModeFlags(cmd)
+}
+
+func addChangeLogModeFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().Bool("journal", false, "Write each change to the changelog immediately (one JSON document per line)")
+	cmd.PersistentFlags().Bool("append", false, "Add changes to an existing changelog")
+	cmd.PersistentFlags().Bool("overwrite", false, "Replace an existing changelog")
+}
+
+func performDrainCommand(cmd *cobra.Command, args []string, p ProviderFunc) {
+	opt := optionsFromDrainCommand(cmd)
+
+	planOut, _ := cmd.PersistentFlags().GetString("plan-out")
+	if len(planOut) > 0 {
+		performPlanCommand(cmd, args, p(cmd), opt, planOut)
+		return
 	}
 
-	logger, err := changelog.NewFileChangeLogger(f)
+	logger, err := changeLoggerFromCommand(cmd)
 	cobra.CheckErr(err)
-	defer flushAndCloseLogger(logger)
 
-	opt := optionsFromDrainCommand(cmd)
-	drainer := d(cmd, logger, opt)
+	drainer := drain.NewDrainer(p(cmd), logger, opt)
 
 	if opt.DryRun {
 		log.Println("Using dry run. No records will be changed.")
@@ -62,12 +90,66 @@ func performDrainCommand(cmd *cobra.Command, args []string, d DrainerFunc) {
 		log.Println("Logic check was disabled. There is no guarantee for a consistent result.")
 	}
 
+	res, err := runDrain(cmd, args, drainer)
+	flushAndCloseLogger(logger)
+	exitWithResult(res, err)
+}
+
+func runDrain(cmd *cobra.Command, args []string, d drain.Drainer) (*result.Result, error) {
 	pattern := args[0]
 	replacement, _ := cmd.PersistentFlags().GetString("replace-by")
 	useRegex, _ := cmd.PersistentFlags().GetBool("use-regex")
 
-	err = performDrain(pattern, replacement, useRegex, drainer)
-	cobra.CheckErr(err)
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	return performDrain(ctx, pattern, replacement, useRegex, d)
+}
+
+func changeLoggerFromCommand(cmd *cobra.Command) (*changelog.FileChangeLogger, error) {
+	f, _ := cmd.PersistentFlags().GetString("file")
+	if len(f) == 0 {
+		return nil, fmt.Errorf("please provide a path for the changelog")
+	}
+
+	return newChangeLogger(cmd, f)
+}
+
+func newChangeLogger(cmd *cobra.Command, f string) (*changelog.FileChangeLogger, error) {
+	mode, err := changeLogFileMode(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var logger *changelog.FileChangeLogger
+	journal, _ := cmd.PersistentFlags().GetBool("journal")
+	if journal {
+		logger, err = changelog.NewFileChangeJournal(f, mode)
+	} else {
+		logger, err = changelog.NewFileChangeLogger(f, mode)
+	}
+
+	if errors.Is(err, changelog.ErrChangeLogExists) {
+		return nil, fmt.Errorf("%w (use --append to add changes or --overwrite to replace it)", err)
+	}
+
+	return logger, err
+}
+
+func changeLogFileMode(cmd *cobra.Command) (changelog.FileMode, error) {
+	appendChanges, _ := cmd.PersistentFlags().GetBool("append")
+	overwrite, _ := cmd.PersistentFlags().GetBool("overwrite")
+
+	switch {
+	case appendChanges && overwrite:
+		return changelog.CreateNew, fmt.Errorf("--append and --overwrite can not be used together")
+	case appendChanges:
+		return changelog.Append, nil
+	case overwrite:
+		return changelog.Overwrite, nil
+	default:
+		return changelog.CreateNew, nil
+	}
 }
 
 func optionsFromDrainCommand(cmd *cobra.Command) *drain.Options {
@@ -76,36 +158,56 @@ func optionsFromDrainCommand(cmd *cobra.Command) *drain.Options {
 	opt.DryRun, _ = cmd.PersistentFlags().GetBool("dry")
 	opt.Force, _ = cmd.PersistentFlags().GetBool("force")
 	opt.Limit, _ = cmd.PersistentFlags().GetInt64("limit")
-	opt.TypeFilter, _ = cmd.PersistentFlags().GetString("type")
+	opt.Wait, _ = cmd.PersistentFlags().GetBool("wait")
+	opt.WaitTimeout, _ = cmd.PersistentFlags().GetDuration("wait-timeout")
+	opt.Parallelism, _ = cmd.PersistentFlags().GetInt("parallelism")
+	opt.Batch, _ = cmd.PersistentFlags().GetBool("batch")
+	setFilterOptions(cmd, opt)
 
-	zoneFilter, _ := cmd.PersistentFlags().GetString("zone")
-	if len(zoneFilter) > 0 {
-		r, err := regexp.Compile(zoneFilter)
-		if err != nil {
-			cobra.CheckErr(fmt.Errorf("invalid zone filter regex: %w", err))
-		}
-		opt.ZoneFilter = r
+	return opt
+}
+
+// filterFlags holds the values of the flags added by addFilterFlags
+type filterFlags struct {
+	zone       *regexp.Regexp
+	skip       *regexp.Regexp
+	name       *regexp.Regexp
+	recordType string
+}
+
+func filterFlagsFromCommand(cmd *cobra.Command) filterFlags {
+	f := filterFlags{
+		zone: regexFromFlag(cmd, "zone"),
+		skip: regexFromFlag(cmd, "skip"),
+		name: regexFromFlag(cmd, "name"),
 	}
+	f.recordType, _ = cmd.PersistentFlags().GetString("type")
 
-	skipFilter, _ := cmd.PersistentFlags().GetString("skip")
-	if len(skipFilter) > 0 {
-		r, err := regexp.Compile(skipFilter)
-		if err != nil {
-			cobra.CheckErr(fmt.Errorf("invalid skip filter regex: %w", err))
-		}
-		opt.SkipFilter = r
+	return f
+}
+
+// regexFromFlag compiles the regex passed by the flag (nil if not set)
+func regexFromFlag(cmd *cobra.Command, name string) *regexp.Regexp {
+	s, _ := cmd.PersistentFlags().GetString(name)
+	if len(s) == 0 {
+		return nil
 	}
 
-	nameFilter, _ := cmd.PersistentFlags().GetString("name")
-	if len(nameFilter) > 0 {
-		r, err := regexp.Compile(nameFilter)
-		if err != nil {
-			cobra.CheckErr(fmt.Errorf("invalid name filter regex: %w", err))
-		}
-		opt.NameFilter = r
+	r, err := regexp.Compile(s)
+	if err != nil {
+		cobra.CheckErr(fmt.Errorf("invalid %s filter regex: %w", name, err))
 	}
 
-	return opt
+	return r
+}
+
+// setFilterOptions sets the zone, skip, name and type filters from the flags added by addFilterFlags
+func setFilterOptions(cmd *cobra.Command, opt *drain.Options) {
+	f := filterFlagsFromCommand(cmd)
+	opt.ZoneFilter = f.zone
+	opt.SkipFilter = f.skip
+	opt.NameFilter = f.name
+	opt.TypeFilter = f.recordType
 }
 
 func flushAndCloseLogger(logger *changelog.FileChangeLogger) {
@@ -116,30 +218,30 @@ func flushAndCloseLogger(logger *changelog.FileChangeLogger) {
 	cobra.CheckErr(err)
 }
 
-func performDrain(pattern, replacement string, useRegex bool, d drain.Drainer) error {
+func performDrain(ctx context.Context, pattern, replacement string, useRegex bool, d drain.Drainer) (*result.Result, error) {
 	if useRegex {
-		return performDrainWithRegex(pattern, replacement, d)
+		return performDrainWithRegex(ctx, pattern, replacement, d)
 	}
 
 	if ipNet, found := extractIPNetwork(pattern); found {
-		return performDrainWithIPNetwork(ipNet, replacement, d)
+		return performDrainWithIPNetwork(ctx, ipNet, replacement, d)
 	}
 
-	return d.DrainWithValue(pattern, replacement)
+	return d.DrainWithValue(ctx, pattern, replacement)
 }
 
-func performDrainWithRegex(pattern, replacement string, d drain.Drainer) error {
+func performDrainWithRegex(ctx context.Context, pattern, replacement string, d drain.Drainer) (*result.Result, error) {
 	r, err := regexp.Compile(pattern)
 	if err != nil {
 		cobra.CheckErr(fmt.Errorf("invalid regex pattern: %w", err))
 	}
 
-	return d.DrainWithRegex(r, replacement)
+	return d.DrainWithRegex(ctx, r, replacement)
 }
 
-func performDrainWithIPNetwork(ipNet *net.IPNet, replacement string, d drain.Drainer) error {
+func performDrainWithIPNetwork(ctx context.Context, ipNet *net.IPNet, replacement string, d drain.Drainer) (*result.Result, error) {
 	if len(replacement) == 0 {
-		return d.DrainWithIpNet(ipNet, nil)
+		return d.DrainWithIpNet(ctx, ipNet, nil)
 	}
 
 	replaceIP := net.ParseIP(replacement)
@@ -147,7 +249,7 @@ func performDrainWithIPNetwork(ipNet *net.IPNet, replacement string, d drain.Dra
 		cobra.CheckErr(fmt.Errorf("please specify valid IP for replacement when using IP as matcher"))
 	}
 
-	return d.DrainWithIpNet(ipNet, replaceIP)
+	return d.DrainWithIpNet(ctx, ipNet, replaceIP)
 }
 
 func extractIPNetwork(s string) (*net.IPNet, bool) {
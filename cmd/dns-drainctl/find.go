@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/czerwonk/dns-drain/pkg/drain"
+	"github.com/czerwonk/dns-drain/pkg/result"
+)
+
+var findOutputFormats = []string{"table", "json", "csv"}
+
+func addFindCommand(cmd *cobra.Command, p ProviderFunc) {
+	findCmd := &cobra.Command{
+		Use:   "find",
+		Short: "Lists records containing an IP, network, value or regex without changing anything",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			performFindCommand(cmd, args, p)
+		},
+	}
+	addFilterFlags(findCmd)
+	findCmd.PersistentFlags().Bool("use-regex", false, "Regex to find data in DNS records")
+	findCmd.PersistentFlags().StringP("output", "o", "table", "Output format (table, json, csv)")
+	findCmd.PersistentFlags().Int("parallelism", defaultParallelism, "Max number of zones read concurrently (0 = unlimited)")
+	findCmd.PersistentFlags().Duration("timeout", defaultTimeout, "Max duration of the search (0 = unlimited)")
+
+	cmd.AddCommand(findCmd)
+}
+
+func performFindCommand(cmd *cobra.Command, args []string, p ProviderFunc) {
+	output, _ := cmd.PersistentFlags().GetString("output")
+	if !slices.Contains(findOutputFormats, output) {
+		cobra.CheckErr(fmt.Errorf("invalid output format %q (valid: %s)", output, strings.Join(findOutputFormats, ", ")))
+	}
+
+	opt := &drain.Options{}
+	opt.Parallelism, _ = cmd.PersistentFlags().GetInt("parallelism")
+	setFilterOptions(cmd, opt)
+
+	finder := drain.NewFinder(p(cmd), opt)
+	useRegex, _ := cmd.PersistentFlags().GetBool("use-regex")
+
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	matches, res, err := performFind(ctx, args[0], useRegex, finder)
+	if err == nil {
+		err = writeMatches(os.Stdout, output, matches)
+	}
+
+	log.Printf("%d matching record sets\n", len(matches))
+	exitWithResult(res, err)
+}
+
+func performFind(ctx context.Context, pattern string, useRegex bool, f *drain.Finder) ([]*drain.Match, *result.Result, error) {
+	if useRegex {
+		r, err := regexp.Compile(pattern)
+		if err != nil {
+			cobra.CheckErr(fmt.Errorf("invalid regex pattern: %w", err))
+		}
+
+		return f.FindWithRegex(ctx, r)
+	}
+
+	if ipNet, found := extractIPNetwork(pattern); found {
+		return f.FindWithIpNet(ctx, ipNet)
+	}
+
+	return f.FindWithValue(ctx, pattern)
+}
+
+func writeMatches(w io.Writer, format string, matches []*drain.Match) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(matches)
+	case "csv":
+		return writeMatchesCSV(w, matches)
+	default:
+		return writeMatchesTable(w, matches)
+	}
+}
+
+func writeMatchesTable(w io.Writer, matches []*drain.Match) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ZONE\tNAME\tTYPE\tTTL\tVALUES\tMATCHED")
+	for _, m := range matches {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n",
+			m.Zone, m.Name, m.Type, m.TTL, strings.Join(m.Values, ","), strings.Join(m.Matched, ","))
+	}
+
+	return tw.Flush()
+}
+
+func writeMatchesCSV(w io.Writer, matches []*drain.Match) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"zone", "name", "type", "ttl", "values", "matched"})
+	for _, m := range matches {
+		cw.Write([]string{m.Zone, m.Name, m.Type, strconv.FormatInt(m.TTL, 10), strings.Join(m.Values, " "), strings.Join(m.Matched, " ")})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/czerwonk/dns-drain/pkg/drain"
+)
+
+func TestWriteMatches(t *testing.T) {
+	matches := []*drain.Match{
+		{Zone: "example.com.", Name: "www.example.com.", Type: "A", TTL: 300,
+			Values: []string{"10.0.0.1", "10.0.0.2"}, Matched: []string{"10.0.0.1"}},
+		{Zone: "example.com.", Name: "example.com.", Type: "TXT", TTL: 3600,
+			Values: []string{`"v=spf1 ip4:10.0.0.1 -all"`}, Matched: []string{`"v=spf1 ip4:10.0.0.1 -all"`}},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "table",
+			want: `ZONE          NAME              TYPE  TTL   VALUES                      MATCHED
+example.com.  www.example.com.  A     300   10.0.0.1,10.0.0.2           10.0.0.1
+example.com.  example.com.      TXT   3600  "v=spf1 ip4:10.0.0.1 -all"  "v=spf1 ip4:10.0.0.1 -all"
+`,
+		},
+		{
+			format: "csv",
+			want: `zone,name,type,ttl,values,matched
+example.com.,www.example.com.,A,300,10.0.0.1 10.0.0.2,10.0.0.1
+example.com.,example.com.,TXT,3600,"""v=spf1 ip4:10.0.0.1 -all""","""v=spf1 ip4:10.0.0.1 -all"""
+`,
+		},
+		{
+			format: "json",
+			want: `[
+  {
+    "zone": "example.com.",
+    "name": "www.example.com.",
+    "type": "A",
+    "ttl": 300,
+    "values": [
+      "10.0.0.1",
+      "10.0.0.2"
+    ],
+    "matched": [
+      "10.0.0.1"
+    ]
+  },
+  {
+    "zone": "example.com.",
+    "name": "example.com.",
+    "type": "TXT",
+    "ttl": 3600,
+    "values": [
+      "\"v=spf1 ip4:10.0.0.1 -all\""
+    ],
+    "matched": [
+      "\"v=spf1 ip4:10.0.0.1 -all\""
+    ]
+  }
+]
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			err := writeMatches(b, test.format, matches)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := b.String(); got != test.want {
+				t.Fatalf("unexpected output:\n%s\nexpected:\n%s", got, test.want)
+			}
+		})
+	}
+}
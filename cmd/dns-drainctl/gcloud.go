@@ -5,12 +5,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
-	"github.com/czerwonk/dns-drain/pkg/changelog"
-	"github.com/czerwonk/dns-drain/pkg/drain"
 	"github.com/czerwonk/dns-drain/pkg/gcloud"
-	"github.com/czerwonk/dns-drain/pkg/undrain"
+	"github.com/czerwonk/dns-drain/pkg/provider"
 	"github.com/spf13/cobra"
 )
 
@@ -27,18 +26,23 @@ func init() {
 
 	gcloudCmd.PersistentFlags().String("project", "", "Name of the Google Cloud project")
 	gcloudCmd.PersistentFlags().String("credentials-file", "", "Path to the cloud credentials file (if not set, cloud SDK will be used)")
-	addDrainCommand(gcloudCmd, g.drainer)
-	addUndrainCommand(gcloudCmd, g.undrainer)
+	gcloudCmd.PersistentFlags().Int("max-attempts", gcloud.DefaultRetryPolicy.MaxAttempts, "Max number of attempts per API call on rate limits and transient errors (1 = no retries)")
+	gcloudCmd.PersistentFlags().Duration("retry-backoff", gcloud.DefaultRetryPolicy.InitialBackoff, "Delay before the first retry (doubled for every further retry)")
+	gcloudCmd.PersistentFlags().Duration("retry-max-backoff", gcloud.DefaultRetryPolicy.MaxBackoff, "Max delay between two attempts")
+	gcloudCmd.PersistentFlags().Float64("requests-per-second", 0, "Max number of API requests per second (0 = unlimited)")
+	addDrainCommand(gcloudCmd, g.provider)
+	addUndrainCommand(gcloudCmd, g.provider)
+	addApplyCommand(gcloudCmd, g.provider)
+	addStatusCommand(gcloudCmd, g.provider)
+	addFindCommand(gcloudCmd, g.provider)
 }
 
-func (g *gcloudCommand) drainer(cmd *cobra.Command, logger changelog.ChangeLogger, opt *drain.Options) drain.Drainer {
+func (g *gcloudCommand) provider(cmd *cobra.Command) provider.Provider {
 	cfg := configFromArgs()
-	return gcloud.NewDrainer(cfg, logger, opt)
-}
+	p, err := gcloud.NewProvider(context.Background(), cfg)
+	cobra.CheckErr(err)
 
-func (g *gcloudCommand) undrainer(cmd *cobra.Command, opt *undrain.Options) undrain.Undrainer {
-	cfg := configFromArgs()
-	return gcloud.NewUndrainer(cfg, opt)
+	return p
 }
 
 func configFromArgs() gcloud.Config {
@@ -47,9 +51,23 @@ func configFromArgs() gcloud.Config {
 		cobra.CheckErr(fmt.Errorf("please specify the Google Cloud project"))
 	}
 
+	maxAttempts, _ := gcloudCmd.PersistentFlags().GetInt("max-attempts")
+	if maxAttempts < 1 {
+		cobra.CheckErr(fmt.Errorf("please specify at least one attempt"))
+	}
+
 	credentialsFile, _ := gcloudCmd.PersistentFlags().GetString("credentials-file")
+	backoff, _ := gcloudCmd.PersistentFlags().GetDuration("retry-backoff")
+	maxBackoff, _ := gcloudCmd.PersistentFlags().GetDuration("retry-max-backoff")
+	rps, _ := gcloudCmd.PersistentFlags().GetFloat64("requests-per-second")
 	return gcloud.Config{
 		Project:         project,
 		CredentialsFile: credentialsFile,
+		Retry: gcloud.RetryPolicy{
+			MaxAttempts:    maxAttempts,
+			InitialBackoff: backoff,
+			MaxBackoff:     maxBackoff,
+		},
+		RequestsPerSecond: rps,
 	}
 }
@@ -30,6 +30,11 @@ $ dns-drainctl gcloud --project api-project-xxx undrain -f drain.json`,
 func init() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(gcloudCmd)
+	rootCmd.AddCommand(route53Cmd)
+	rootCmd.AddCommand(rfc2136Cmd)
+	rootCmd.AddCommand(cloudflareCmd)
+	rootCmd.AddCommand(powerdnsCmd)
+	rootCmd.AddCommand(zonefileCmd)
 }
 
 func main() {
@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/czerwonk/dns-drain/pkg/changelog"
+	"github.com/czerwonk/dns-drain/pkg/drain"
+	"github.com/czerwonk/dns-drain/pkg/plan"
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+// discardChangeLogger drops all changes. Changes recorded in a plan are logged when the plan is applied.
+type discardChangeLogger struct{}
+
+func (discardChangeLogger) LogChange(changelog.DnsChange) error {
+	return nil
+}
+
+func performPlanCommand(cmd *cobra.Command, args []string, p provider.Provider, opt *drain.Options, path string) {
+	if opt.DryRun {
+		cobra.CheckErr(fmt.Errorf("--plan-out can not be used together with --dry"))
+	}
+
+	overwrite, _ := cmd.PersistentFlags().GetBool("plan-overwrite")
+	err := plan.CheckNew(path, overwrite)
+	if err != nil {
+		cobra.CheckErr(fmt.Errorf("%w (use --plan-overwrite to replace it)", err))
+	}
+
+	log.Println("Writing plan. No records will be changed.")
+
+	recorder := plan.NewRecorder(p)
+	drainer := drain.NewDrainer(recorder, discardChangeLogger{}, opt)
+
+	res, err := runDrain(cmd, args, drainer)
+	if res != nil {
+		res.Planned = true
+	}
+
+	if err == nil {
+		pl := recorder.Plan()
+		err = pl.Save(path, overwrite)
+		if err == nil {
+			log.Printf("Plan with %d changes written to %s\n", len(pl.Changes), path)
+		}
+	}
+
+	exitWithResult(res, err)
+}
+
+func addApplyCommand(cmd *cobra.Command, p ProviderFunc) {
+	applyCmd := &cobra.Command{
+		Use:   "apply [plan file]",
+		Short: "Applies the changes of a plan written by drain --plan-out",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			performApplyCommand(cmd, args, p)
+		},
+	}
+	addChangeLogFlags(applyCmd)
+	applyCmd.PersistentFlags().Int("parallelism", defaultParallelism, "Max number of zones processed concurrently (0 = unlimited)")
+	applyCmd.PersistentFlags().Duration("timeout", defaultTimeout, "Max duration of the apply (0 = unlimited)")
+
+	cmd.AddCommand(applyCmd)
+}
+
+func performApplyCommand(cmd *cobra.Command, args []string, p ProviderFunc) {
+	pl, err := plan.Load(args[0])
+	cobra.CheckErr(err)
+
+	logger, err := changeLoggerFromCommand(cmd)
+	cobra.CheckErr(err)
+
+	parallelism, _ := cmd.PersistentFlags().GetInt("parallelism")
+	applier := drain.NewPlanApplier(p(cmd), logger, parallelism)
+
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	res, err := applier.Apply(ctx, pl)
+	flushAndCloseLogger(logger)
+	exitWithResult(res, err)
+}
@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/czerwonk/dns-drain/pkg/powerdns"
+	"github.com/czerwonk/dns-drain/pkg/provider"
+	"github.com/spf13/cobra"
+)
+
+type powerdnsCommand struct{}
+
+var powerdnsCmd = &cobra.Command{
+	Use:     "powerdns",
+	Aliases: []string{"pdns"},
+	Short:   "Drain and undrain DNS records using PowerDNS Authoritative HTTP API",
+}
+
+func init() {
+	p := &powerdnsCommand{}
+
+	powerdnsCmd.PersistentFlags().String("url", "http://localhost:8081", "Base URL of the PowerDNS API")
+	powerdnsCmd.PersistentFlags().String("api-key", "", "PowerDNS API key (if not set, PDNS_API_KEY will be used)")
+	powerdnsCmd.PersistentFlags().String("server-id", "localhost", "PowerDNS server ID")
+	addDrainCommand(powerdnsCmd, p.provider)
+	addUndrainCommand(powerdnsCmd, p.provider)
+	addApplyCommand(powerdnsCmd, p.provider)
+	addStatusCommand(powerdnsCmd, p.provider)
+	addFindCommand(powerdnsCmd, p.provider)
+}
+
+func (p *powerdnsCommand) provider(cmd *cobra.Command) provider.Provider {
+	return powerdns.NewProvider(powerdnsConfigFromArgs())
+}
+
+func powerdnsConfigFromArgs() powerdns.Config {
+	url, _ := powerdnsCmd.PersistentFlags().GetString("url")
+	if url == "" {
+		cobra.CheckErr(fmt.Errorf("please specify the PowerDNS API URL"))
+	}
+
+	apiKey, _ := powerdnsCmd.PersistentFlags().GetString("api-key")
+	if apiKey == "" {
+		apiKey = os.Getenv("PDNS_API_KEY")
+	}
+
+	serverID, _ := powerdnsCmd.PersistentFlags().GetString("server-id")
+	return powerdns.Config{
+		URL:      url,
+		ApiKey:   apiKey,
+		ServerID: serverID,
+	}
+}
@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/czerwonk/dns-drain/pkg/result"
+)
+
+const (
+	exitCodePartialFailure = 2
+	exitCodeTotalFailure   = 3
+)
+
+// exitWithResult prints the summary of the run and exits with a code reflecting the result
+func exitWithResult(res *result.Result, err error) {
+	if res != nil {
+		log.Printf("Summary - %s\n", res)
+	}
+
+	cobra.CheckErr(err)
+
+	switch res.Status() {
+	case result.PartialFailure:
+		fmt.Fprintf(os.Stderr, "Error: %d zones and %d records failed\n", res.ZonesFailed, res.RecordsFailed)
+		os.Exit(exitCodePartialFailure)
+	case result.TotalFailure:
+		fmt.Fprintf(os.Stderr, "Error: no records changed, %d zones and %d records failed\n", res.ZonesFailed, res.RecordsFailed)
+		os.Exit(exitCodeTotalFailure)
+	}
+}
@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/czerwonk/dns-drain/pkg/result"
+)
+
+var exitTestResults = map[string]func() *result.Result{
+	"success": func() *result.Result {
+		r := result.NewResult()
+		r.AddZone(1)
+		r.AddChanged()
+		return r
+	},
+	"partial failure": func() *result.Result {
+		r := result.NewResult()
+		r.AddChanged()
+		r.AddZoneFailure("example.org.", errors.New("zone not readable"))
+		return r
+	},
+	"total failure": func() *result.Result {
+		r := result.NewResult()
+		r.AddRecordFailure("example.com.", "www.example.com.", "A", errors.New("update failed"))
+		return r
+	},
+}
+
+// TestExitWithResult runs exitWithResult in a child process, as it terminates the process
+func TestExitWithResult(t *testing.T) {
+	if name := os.Getenv("EXIT_WITH_RESULT"); name != "" {
+		exitWithResult(exitTestResults[name](), nil)
+		return
+	}
+
+	tests := []struct {
+		name string
+		want int
+	}{
+		{name: "success", want: 0},
+		{name: "partial failure", want: exitCodePartialFailure},
+		{name: "total failure", want: exitCodeTotalFailure},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestExitWithResult$")
+			cmd.Env = append(os.Environ(), "EXIT_WITH_RESULT="+test.name)
+
+			err := cmd.Run()
+
+			code := 0
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				code = exitErr.ExitCode()
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if code != test.want {
+				t.Fatalf("expected exit code %d, got %d", test.want, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+	"github.com/czerwonk/dns-drain/pkg/rfc2136"
+	"github.com/spf13/cobra"
+)
+
+type rfc2136Command struct{}
+
+var rfc2136Cmd = &cobra.Command{
+	Use:     "rfc2136",
+	Aliases: []string{"nsupdate"},
+	Short:   "Drain and undrain DNS records using zone transfers and dynamic updates (RFC 2136)",
+}
+
+func init() {
+	r := &rfc2136Command{}
+
+	rfc2136Cmd.PersistentFlags().String("server", "", "Authoritative server to send AXFR and UPDATE requests to (host:port)")
+	rfc2136Cmd.PersistentFlags().StringSlice("zones", []string{}, "Zones to drain/undrain")
+	rfc2136Cmd.PersistentFlags().String("tsig-key", "", "Name of the TSIG key (if not set, requests are not signed)")
+	rfc2136Cmd.PersistentFlags().String("tsig-secret", "", "Base64 encoded TSIG secret")
+	rfc2136Cmd.PersistentFlags().String("tsig-algorithm", "hmac-sha256", "TSIG algorithm")
+	addDrainCommand(rfc2136Cmd, r.provider)
+	addUndrainCommand(rfc2136Cmd, r.provider)
+	addApplyCommand(rfc2136Cmd, r.provider)
+	addStatusCommand(rfc2136Cmd, r.provider)
+	addFindCommand(rfc2136Cmd, r.provider)
+}
+
+func (r *rfc2136Command) provider(cmd *cobra.Command) provider.Provider {
+	return rfc2136.NewProvider(rfc2136ConfigFromArgs())
+}
+
+func rfc2136ConfigFromArgs() rfc2136.Config {
+	server, _ := rfc2136Cmd.PersistentFlags().GetString("server")
+	if server == "" {
+		cobra.CheckErr(fmt.Errorf("please specify the DNS server"))
+	}
+
+	zones, _ := rfc2136Cmd.PersistentFlags().GetStringSlice("zones")
+	if len(zones) == 0 {
+		cobra.CheckErr(fmt.Errorf("please specify at least one zone"))
+	}
+
+	tsigKey, _ := rfc2136Cmd.PersistentFlags().GetString("tsig-key")
+	tsigSecret, _ := rfc2136Cmd.PersistentFlags().GetString("tsig-secret")
+	tsigAlgorithm, _ := rfc2136Cmd.PersistentFlags().GetString("tsig-algorithm")
+	if tsigKey != "" && tsigSecret == "" {
+		cobra.CheckErr(fmt.Errorf("please specify the TSIG secret"))
+	}
+
+	return rfc2136.Config{
+		Server:        server,
+		Zones:         zones,
+		TsigKey:       tsigKey,
+		TsigSecret:    tsigSecret,
+		TsigAlgorithm: tsigAlgorithm,
+	}
+}
@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"context"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+	"github.com/czerwonk/dns-drain/pkg/route53"
+	"github.com/spf13/cobra"
+)
+
+type route53Command struct{}
+
+var route53Cmd = &cobra.Command{
+	Use:     "route53",
+	Aliases: []string{"aws"},
+	Short:   "Drain and undrain DNS records using AWS Route 53 API",
+}
+
+func init() {
+	r := &route53Command{}
+
+	route53Cmd.PersistentFlags().String("profile", "", "Name of the AWS shared config profile (if not set, default credential chain will be used)")
+	route53Cmd.PersistentFlags().String("region", "us-east-1", "AWS region used to sign requests")
+	route53Cmd.PersistentFlags().String("endpoint", "", "Custom Route 53 API endpoint URL")
+	addDrainCommand(route53Cmd, r.provider)
+	addUndrainCommand(route53Cmd, r.provider)
+	addApplyCommand(route53Cmd, r.provider)
+	addStatusCommand(route53Cmd, r.provider)
+	addFindCommand(route53Cmd, r.provider)
+}
+
+func (r *route53Command) provider(cmd *cobra.Command) provider.Provider {
+	p, err := route53.NewProvider(context.Background(), route53ConfigFromArgs())
+	cobra.CheckErr(err)
+
+	return p
+}
+
+func route53ConfigFromArgs() route53.Config {
+	profile, _ := route53Cmd.PersistentFlags().GetString("profile")
+	region, _ := route53Cmd.PersistentFlags().GetString("region")
+	endpoint, _ := route53Cmd.PersistentFlags().GetString("endpoint")
+
+	return route53.Config{
+		Profile:  profile,
+		Region:   region,
+		Endpoint: endpoint,
+	}
+}
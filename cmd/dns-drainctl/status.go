@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/czerwonk/dns-drain/pkg/changelog"
+	"github.com/czerwonk/dns-drain/pkg/status"
+)
+
+func addStatusCommand(cmd *cobra.Command, p ProviderFunc) {
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Shows if the changes of a changelog are still applied, reverted or drifted",
+		Run: func(cmd *cobra.Command, args []string) {
+			performStatusCommand(cmd, args, p)
+		},
+	}
+	statusCmd.PersistentFlags().StringP("file", "f", "drain.json", "Changelog file")
+	statusCmd.PersistentFlags().StringP("output", "o", "table", "Output format (table, json)")
+	statusCmd.PersistentFlags().Int("parallelism", defaultParallelism, "Max number of zones read concurrently (0 = unlimited)")
+	statusCmd.PersistentFlags().Duration("timeout", defaultTimeout, "Max duration of the status check (0 = unlimited)")
+
+	cmd.AddCommand(statusCmd)
+}
+
+func performStatusCommand(cmd *cobra.Command, _ []string, p ProviderFunc) {
+	f, _ := cmd.PersistentFlags().GetString("file")
+	if len(f) == 0 {
+		cobra.CheckErr(fmt.Errorf("please provide a path for the changelog"))
+	}
+
+	output, _ := cmd.PersistentFlags().GetString("output")
+	if output != "table" && output != "json" {
+		cobra.CheckErr(fmt.Errorf("invalid output format %q (valid: table, json)", output))
+	}
+
+	c, err := changelog.NewFileChangeLog(f).GetChanges()
+	cobra.CheckErr(err)
+
+	parallelism, _ := cmd.PersistentFlags().GetInt("parallelism")
+	checker := status.NewChecker(p(cmd), parallelism)
+
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	report, err := checker.Check(ctx, c)
+	cobra.CheckErr(err)
+
+	if output == "json" {
+		err = writeStatusJSON(os.Stdout, report)
+	} else {
+		err = writeStatusTable(os.Stdout, report)
+	}
+	cobra.CheckErr(err)
+}
+
+func writeStatusJSON(w io.Writer, report *status.Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+func writeStatusTable(w io.Writer, report *status.Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATE\tZONE\tRECORD\tTYPE\tACTION\tVALUE\tLIVE\tREASON")
+	for _, c := range report.Changes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			c.State, c.Zone, c.Record, c.RecordType, c.Action, c.Value, strings.Join(c.Live, ","), c.Reason)
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "\n%d applied, %d reverted, %d drifted, %d unknown\n",
+		report.Summary[status.Applied], report.Summary[status.Reverted], report.Summary[status.Drifted], report.Summary[status.Unknown])
+	return err
+}
@@ -7,7 +7,6 @@ package main
 import (
 	"fmt"
 	"log"
-	"regexp"
 
 	"github.com/spf13/cobra"
 
@@ -15,26 +14,32 @@ import (
 	"github.com/czerwonk/dns-drain/pkg/undrain"
 )
 
-type UndrainerFunc func(*cobra.Command, *undrain.Options) undrain.Undrainer
-
-func addUndrainCommand(cmd *cobra.Command, u UndrainerFunc) {
+func addUndrainCommand(cmd *cobra.Command, p ProviderFunc) {
 	undrainCmd := &cobra.Command{
 		Use:   "undrain",
 		Short: "Rollback DNS changes by using the changelog file",
 		Run: func(cmd *cobra.Command, args []string) {
-			performUndrainCommand(cmd, args, u)
+			performUndrainCommand(cmd, args, p)
 		},
 	}
 	undrainCmd.PersistentFlags().Bool("dry", false, "Do not modify DNS records (simulation only)")
 	undrainCmd.PersistentFlags().StringP("file", "f", "drain.json", "File containing changes to revert")
-	undrainCmd.PersistentFlags().StringP("zone", "z", "", "Apply only to zones matching the specified regex")
-	undrainCmd.PersistentFlags().String("skip", "", "Skip zones matching the specified regex")
+	undrainCmd.PersistentFlags().String("out", "", "Changelog file for the reverted changes (can be used to revert the undrain)")
+	addChangeLogModeFlags(undrainCmd)
+	addFilterFlags(undrainCmd)
+	undrainCmd.PersistentFlags().String("value", "", "Only revert changes of this value")
+	undrainCmd.PersistentFlags().String("net", "", "Only revert changes of IPs in this network (CIDR)")
 	undrainCmd.PersistentFlags().Int64("limit", -1, "Max number of records to change (-1 = unlimited)")
+	undrainCmd.PersistentFlags().String("on-conflict", string(undrain.ConflictSkip), "Handling of records changed after the drain (skip, merge, overwrite)")
+	undrainCmd.PersistentFlags().Int("parallelism", defaultParallelism, "Max number of zones processed concurrently (0 = unlimited)")
+	undrainCmd.PersistentFlags().Bool("wait", false, "Wait until all changes are completed by the provider")
+	undrainCmd.PersistentFlags().Duration("wait-timeout", defaultWaitTimeout, "Max duration to wait for changes to complete (0 = unlimited)")
+	undrainCmd.PersistentFlags().Duration("timeout", defaultTimeout, "Max duration of the undrain (0 = unlimited)")
 
 	cmd.AddCommand(undrainCmd)
 }
 
-func performUndrainCommand(cmd *cobra.Command, _ []string, u UndrainerFunc) {
+func performUndrainCommand(cmd *cobra.Command, _ []string, p ProviderFunc) {
 	f, _ := cmd.PersistentFlags().GetString("file")
 	if len(f) == 0 {
 		cobra.CheckErr(fmt.Errorf("please provide a path for the changelog source file"))
@@ -45,14 +50,41 @@ func performUndrainCommand(cmd *cobra.Command, _ []string, u UndrainerFunc) {
 	cobra.CheckErr(err)
 
 	opt := optionsFromUndrainCommand(cmd)
-	undrainer := u(cmd, opt)
+	out, _ := cmd.PersistentFlags().GetString("out")
+	var logger changelog.ChangeLogger = discardChangeLogger{}
+	if len(out) > 0 {
+		fileLogger, err := newChangeLogger(cmd, out)
+		cobra.CheckErr(err)
+		logger = fileLogger
+	}
+
+	undrainer := undrain.NewUndrainer(p(cmd), logger, opt)
 
 	if opt.DryRun {
 		log.Println("Using dry run. No records will be changed.")
 	}
 
-	err = undrainer.Undrain(c)
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	res, err := undrainer.Undrain(ctx, c)
+	if fileLogger, ok := logger.(*changelog.FileChangeLogger); ok {
+		flushAndCloseLogger(fileLogger)
+	}
+
+	if !opt.DryRun && opt.SelectsSubset() {
+		updateChangeLog(f, undrainer.Outstanding(c))
+	}
+
+	exitWithResult(res, err)
+}
+
+// updateChangeLog replaces the changelog by the changes still to be reverted
+func updateChangeLog(f string, outstanding *changelog.DnsChangeSet) {
+	err := changelog.WriteChangeSet(f, outstanding)
 	cobra.CheckErr(err)
+
+	log.Printf("%d changes still outstanding in %s\n", len(outstanding.Changes), f)
 }
 
 func optionsFromUndrainCommand(cmd *cobra.Command) *undrain.Options {
@@ -60,23 +92,29 @@ func optionsFromUndrainCommand(cmd *cobra.Command) *undrain.Options {
 
 	opt.DryRun, _ = cmd.PersistentFlags().GetBool("dry")
 	opt.Limit, _ = cmd.PersistentFlags().GetInt64("limit")
+	opt.Wait, _ = cmd.PersistentFlags().GetBool("wait")
+	opt.WaitTimeout, _ = cmd.PersistentFlags().GetDuration("wait-timeout")
+	opt.Parallelism, _ = cmd.PersistentFlags().GetInt("parallelism")
+	opt.ValueFilter, _ = cmd.PersistentFlags().GetString("value")
 
-	zoneFilter, _ := cmd.PersistentFlags().GetString("zone")
-	if len(zoneFilter) > 0 {
-		r, err := regexp.Compile(zoneFilter)
-		if err != nil {
-			cobra.CheckErr(fmt.Errorf("invalid zone filter regex: %w", err))
-		}
-		opt.ZoneFilter = r
-	}
-
-	skipFilter, _ := cmd.PersistentFlags().GetString("skip")
-	if len(skipFilter) > 0 {
-		r, err := regexp.Compile(skipFilter)
-		if err != nil {
-			cobra.CheckErr(fmt.Errorf("invalid skip filter regex: %w", err))
+	onConflict, _ := cmd.PersistentFlags().GetString("on-conflict")
+	policy, err := undrain.ParseConflictPolicy(onConflict)
+	cobra.CheckErr(err)
+	opt.OnConflict = policy
+
+	f := filterFlagsFromCommand(cmd)
+	opt.ZoneFilter = f.zone
+	opt.SkipFilter = f.skip
+	opt.NameFilter = f.name
+	opt.TypeFilter = f.recordType
+
+	netFilter, _ := cmd.PersistentFlags().GetString("net")
+	if len(netFilter) > 0 {
+		ipNet, found := extractIPNetwork(netFilter)
+		if !found {
+			cobra.CheckErr(fmt.Errorf("invalid network: %s", netFilter))
 		}
-		opt.SkipFilter = r
+		opt.NetFilter = ipNet
 	}
 
 	return opt
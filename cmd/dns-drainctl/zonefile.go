@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+	"github.com/czerwonk/dns-drain/pkg/zonefile"
+	"github.com/spf13/cobra"
+)
+
+type zonefileCommand struct{}
+
+var zonefileCmd = &cobra.Command{
+	Use:     "zonefile",
+	Aliases: []string{"bind"},
+	Short:   "Drain and undrain DNS records by rewriting BIND zone files",
+}
+
+func init() {
+	z := &zonefileCommand{}
+
+	zonefileCmd.PersistentFlags().String("dir", ".", "Directory containing the zone files")
+	zonefileCmd.PersistentFlags().String("pattern", zonefile.DefaultPattern, "Glob pattern to select zone files in the directory")
+	addDrainCommand(zonefileCmd, z.provider)
+	addUndrainCommand(zonefileCmd, z.provider)
+	addApplyCommand(zonefileCmd, z.provider)
+	addStatusCommand(zonefileCmd, z.provider)
+	addFindCommand(zonefileCmd, z.provider)
+}
+
+func (z *zonefileCommand) provider(cmd *cobra.Command) provider.Provider {
+	return zonefile.NewProvider(zonefileConfigFromArgs())
+}
+
+func zonefileConfigFromArgs() zonefile.Config {
+	dir, _ := zonefileCmd.PersistentFlags().GetString("dir")
+	if dir == "" {
+		cobra.CheckErr(fmt.Errorf("please specify the zone file directory"))
+	}
+
+	pattern, _ := zonefileCmd.PersistentFlags().GetString("pattern")
+	return zonefile.Config{
+		Dir:     dir,
+		Pattern: pattern,
+	}
+}
@@ -4,6 +4,13 @@
 
 package changelog
 
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
 const (
 	Add    string = "+"
 	Remove string = "-"
@@ -14,12 +21,32 @@ type DnsChangeSet struct {
 }
 
 type DnsChange struct {
-	Provider   string `json:"provider"`
-	Action     string `json:"action"`
-	Zone       string `json:"zone"`
-	Record     string `json:"record"`
-	RecordType string `json:"recordType"`
-	Value      string `json:"value"`
+	Provider   string            `json:"provider"`
+	Action     string            `json:"action"`
+	Zone       string            `json:"zone"`
+	Record     string            `json:"record"`
+	RecordType string            `json:"recordType"`
+	Value      string            `json:"value"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// state of the record set before and after the change. Before and After are nil for changelogs
+	// written without state, empty if the record set did not exist.
+	TTL           int64           `json:"ttl,omitempty"`
+	RoutingPolicy json.RawMessage `json:"routingPolicy,omitempty"`
+	Before        []string        `json:"before"`
+	After         []string        `json:"after"`
+
+	Timestamp time.Time `json:"timestamp,omitzero"`
+	Project   string    `json:"project,omitempty"`
+	RunID     string    `json:"runId,omitempty"`
+}
+
+// NewRunID generates a random identifier to correlate all changes of a run
+func NewRunID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
 }
 
 func (c *DnsChangeSet) GroupByZone() map[string][]DnsChange {
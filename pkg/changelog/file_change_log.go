@@ -5,7 +5,10 @@
 package changelog
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"log"
 	"os"
 )
 
@@ -17,6 +20,7 @@ func NewFileChangeLog(file string) *FileChangeLog {
 	return &FileChangeLog{filename: file}
 }
 
+// GetChanges reads the changes from a single JSON document or a journal with one change per line
 func (l *FileChangeLog) GetChanges() (*DnsChangeSet, error) {
 	b, err := os.ReadFile(l.filename)
 	if err != nil {
@@ -25,9 +29,46 @@ func (l *FileChangeLog) GetChanges() (*DnsChangeSet, error) {
 
 	c := &DnsChangeSet{}
 	err = json.Unmarshal(b, c)
-	if err != nil {
-		return nil, err
+	if err == nil && c.Changes != nil {
+		return c, nil
+	}
+
+	return parseJournal(b)
+}
+
+func parseJournal(b []byte) (*DnsChangeSet, error) {
+	c := &DnsChangeSet{Changes: make([]DnsChange, 0)}
+
+	lines := bytes.Split(b, []byte("\n"))
+	for i, line := range lines {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		change := DnsChange{}
+		err := json.Unmarshal(line, &change)
+		if err != nil {
+			if isLastLine(lines, i) {
+				log.Printf("WARN - Ignoring incomplete last line %d of changelog: %s\n", i+1, err)
+				break
+			}
+
+			return nil, fmt.Errorf("invalid change in line %d: %w", i+1, err)
+		}
+
+		c.Changes = append(c.Changes, change)
 	}
 
 	return c, nil
 }
+
+func isLastLine(lines [][]byte, idx int) bool {
+	for _, l := range lines[idx+1:] {
+		if len(bytes.TrimSpace(l)) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
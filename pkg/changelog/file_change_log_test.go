@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package changelog
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestGetChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "legacy document",
+			content: `{"changes":[{"action":"-","record":"a.example.com.","value":"10.0.0.1"},{"action":"+","record":"a.example.com.","value":"10.0.0.2"}]}`,
+			want:    []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			name:    "legacy document without changes",
+			content: `{"changes":[]}`,
+			want:    []string{},
+		},
+		{
+			name: "journal",
+			content: `{"action":"-","record":"a.example.com.","value":"10.0.0.1"}
+{"action":"+","record":"a.example.com.","value":"10.0.0.2"}
+`,
+			want: []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			name: "journal with truncated last line",
+			content: `{"action":"-","record":"a.example.com.","value":"10.0.0.1"}
+{"action":"+","record":"a.exa`,
+			want: []string{"10.0.0.1"},
+		},
+		{
+			name: "journal with invalid line",
+			content: `{"action":"-","record":"a.exa
+{"action":"+","record":"a.example.com.","value":"10.0.0.2"}
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "changes.json")
+			err := os.WriteFile(path, []byte(test.content), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := NewFileChangeLog(path).GetChanges()
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := changeValues(c.Changes); !slices.Equal(got, test.want) {
+				t.Fatalf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestParseJournal(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "empty",
+			content: "",
+			want:    []string{},
+		},
+		{
+			name:    "blank lines ignored",
+			content: "\n{\"value\":\"10.0.0.1\"}\n\n{\"value\":\"10.0.0.2\"}\n\n",
+			want:    []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			name:    "truncated last line followed by blank lines",
+			content: "{\"value\":\"10.0.0.1\"}\n{\"val\n\n",
+			want:    []string{"10.0.0.1"},
+		},
+		{
+			name:    "invalid line in between",
+			content: "{\"value\":\"10.0.0.1\"}\nnot json\n{\"value\":\"10.0.0.2\"}\n",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := parseJournal([]byte(test.content))
+			if test.wantErr != (err != nil) {
+				t.Fatalf("expected error=%v, got %v", test.wantErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if got := changeValues(c.Changes); !slices.Equal(got, test.want) {
+				t.Fatalf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestChangeState(t *testing.T) {
+	tests := []struct {
+		name   string
+		before []string
+		after  []string
+	}{
+		{
+			name: "without state",
+		},
+		{
+			name:   "record set removed",
+			before: []string{"10.0.0.1"},
+			after:  []string{},
+		},
+		{
+			name:   "record set created",
+			before: []string{},
+			after:  []string{"10.0.0.1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "changes.json")
+			err := WriteChangeSet(path, &DnsChangeSet{Changes: []DnsChange{{Action: Remove, Value: "10.0.0.1", Before: test.before, After: test.after}}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := NewFileChangeLog(path).GetChanges()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertState(t, c.Changes[0].Before, test.before)
+			assertState(t, c.Changes[0].After, test.after)
+		})
+	}
+}
+
+// assertState checks the values, distinguishing an empty record set from a missing state
+func assertState(t *testing.T, got, want []string) {
+	t.Helper()
+
+	if (got == nil) != (want == nil) || !slices.Equal(got, want) {
+		t.Fatalf("expected %#v, got %#v", want, got)
+	}
+}
+
+func changeValues(changes []DnsChange) []string {
+	values := make([]string, 0, len(changes))
+	for _, c := range changes {
+		values = append(values, c.Value)
+	}
+
+	return values
+}
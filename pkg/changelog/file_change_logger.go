@@ -5,58 +5,222 @@
 package changelog
 
 import (
-	"bufio"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 )
 
+// FileMode defines how an existing changelog file is handled
+type FileMode int
+
+const (
+	// CreateNew refuses to use a file already containing changes
+	CreateNew FileMode = iota
+
+	// Overwrite replaces existing changes
+	Overwrite
+
+	// Append keeps existing changes and adds the new ones
+	Append
+)
+
+var ErrChangeLogExists = errors.New("changelog already contains changes")
+
 type FileChangeLogger struct {
 	mutex   sync.Mutex
+	path    string
 	file    *os.File
 	changes []DnsChange
+	journal bool
 }
 
-func NewFileChangeLogger(filePath string) (*FileChangeLogger, error) {
-	f, err := os.Create(filePath)
+// NewFileChangeLogger creates a logger writing all changes as one JSON document to the file on Flush
+func NewFileChangeLogger(filePath string, mode FileMode) (*FileChangeLogger, error) {
+	existing, err := existingChanges(filePath, mode)
 	if err != nil {
 		return nil, err
 	}
 
-	return &FileChangeLogger{file: f, changes: make([]DnsChange, 0)}, nil
+	err = checkWritable(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileChangeLogger{path: filePath, changes: existing}, nil
+}
+
+// checkWritable ensures the file can be written on Flush, so errors are detected before any change is applied
+func checkWritable(filePath string) error {
+	info, err := os.Stat(filePath)
+	if err == nil && info.IsDir() {
+		return fmt.Errorf("%s is a directory", filePath)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*")
+	if err != nil {
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+
+		return fmt.Errorf("%s is not writable: %w", filePath, err)
+	}
+
+	tmp.Close()
+	return os.Remove(tmp.Name())
+}
+
+// NewFileChangeJournal creates a logger appending each change as JSON line to the file.
+// Every change is synced to disk before LogChange returns, so the file is usable even if the process is killed.
+func NewFileChangeJournal(filePath string, mode FileMode) (*FileChangeLogger, error) {
+	existing, err := existingChanges(filePath, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 0)
+	for _, c := range existing {
+		line, err := json.Marshal(c)
+		if err != nil {
+			return nil, err
+		}
+
+		b = append(append(b, line...), '\n')
+	}
+
+	err = writeFile(filePath, b)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(filePath, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileChangeLogger{path: filePath, file: f, journal: true}, nil
+}
+
+func existingChanges(filePath string, mode FileMode) ([]DnsChange, error) {
+	if mode == Overwrite {
+		return make([]DnsChange, 0), nil
+	}
+
+	info, err := os.Stat(filePath)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && info.Size() == 0) {
+		return make([]DnsChange, 0), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := NewFileChangeLog(filePath).GetChanges()
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid changelog: %w", filePath, err)
+	}
+
+	if mode == CreateNew && len(c.Changes) > 0 {
+		return nil, fmt.Errorf("%s: %w", filePath, ErrChangeLogExists)
+	}
+
+	return c.Changes, nil
 }
 
 func (l *FileChangeLogger) LogChange(c DnsChange) error {
 	l.mutex.Lock()
 	defer l.mutex.Unlock()
 
+	if l.journal {
+		return l.appendChange(c)
+	}
+
 	l.changes = append(l.changes, c)
 
 	return nil
 }
 
-func (l *FileChangeLogger) Flush() error {
-	c := DnsChangeSet{Changes: l.changes}
-
+func (l *FileChangeLogger) appendChange(c DnsChange) error {
 	b, err := json.Marshal(c)
 	if err != nil {
 		return err
 	}
 
-	w := bufio.NewWriter(l.file)
-	_, err = w.Write(b)
+	_, err = l.file.Write(append(b, '\n'))
 	if err != nil {
 		return err
 	}
 
-	err = w.Flush()
+	return l.file.Sync()
+}
+
+func (l *FileChangeLogger) Flush() error {
+	if l.journal {
+		return nil
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	c := DnsChangeSet{Changes: l.changes}
+
+	b, err := json.Marshal(c)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return writeFile(l.path, b)
 }
 
 func (l *FileChangeLogger) Close() error {
+	if l.file == nil {
+		return nil
+	}
+
 	return l.file.Close()
 }
+
+// WriteChangeSet replaces the changelog file by the given changes
+func WriteChangeSet(path string, c *DnsChangeSet) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(path, b)
+}
+
+// writeFile replaces the file atomically, so the previous content is kept if writing fails
+func writeFile(path string, b []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(b)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+
+	err = tmp.Sync()
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(tmp.Name(), 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,177 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package changelog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const existingChangeLog = `{"changes":[{"action":"-","record":"a.example.com.","value":"10.0.0.1"}]}`
+
+func TestExistingChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		missing bool
+		content string
+		mode    FileMode
+		want    []string
+		wantErr error
+	}{
+		{
+			name:    "missing file",
+			missing: true,
+			mode:    CreateNew,
+			want:    []string{},
+		},
+		{
+			name:    "empty file",
+			content: "",
+			mode:    CreateNew,
+			want:    []string{},
+		},
+		{
+			name:    "create new refuses existing changes",
+			content: existingChangeLog,
+			mode:    CreateNew,
+			wantErr: ErrChangeLogExists,
+		},
+		{
+			name:    "append keeps existing changes",
+			content: existingChangeLog,
+			mode:    Append,
+			want:    []string{"10.0.0.1"},
+		},
+		{
+			name:    "overwrite ignores existing changes",
+			content: existingChangeLog,
+			mode:    Overwrite,
+			want:    []string{},
+		},
+		{
+			name:    "overwrite ignores invalid file",
+			content: "not json\nnot json",
+			mode:    Overwrite,
+			want:    []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "changes.json")
+			if !test.missing {
+				writeTestFile(t, path, test.content)
+			}
+
+			changes, err := existingChanges(path, test.mode)
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("expected %v, got %v", test.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := changeValues(changes); !slices.Equal(got, test.want) {
+				t.Fatalf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestFileChangeLoggerModes(t *testing.T) {
+	loggers := []struct {
+		name string
+		new  func(string, FileMode) (*FileChangeLogger, error)
+	}{
+		{name: "document", new: NewFileChangeLogger},
+		{name: "journal", new: NewFileChangeJournal},
+	}
+
+	tests := []struct {
+		name    string
+		mode    FileMode
+		want    []string
+		wantErr error
+	}{
+		{
+			name:    "create new",
+			mode:    CreateNew,
+			wantErr: ErrChangeLogExists,
+		},
+		{
+			name: "append",
+			mode: Append,
+			want: []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			name: "overwrite",
+			mode: Overwrite,
+			want: []string{"10.0.0.2"},
+		},
+	}
+
+	for _, l := range loggers {
+		for _, test := range tests {
+			t.Run(l.name+"/"+test.name, func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "changes.json")
+				writeTestFile(t, path, existingChangeLog)
+
+				logger, err := l.new(path, test.mode)
+				if test.wantErr != nil {
+					if !errors.Is(err, test.wantErr) {
+						t.Fatalf("expected %v, got %v", test.wantErr, err)
+					}
+
+					if b, _ := os.ReadFile(path); string(b) != existingChangeLog {
+						t.Fatalf("expected existing changelog to be kept, got %s", b)
+					}
+					return
+				}
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				err = logger.LogChange(DnsChange{Action: Add, Record: "a.example.com.", Value: "10.0.0.2"})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if err := logger.Flush(); err != nil {
+					t.Fatal(err)
+				}
+
+				if err := logger.Close(); err != nil {
+					t.Fatal(err)
+				}
+
+				c, err := NewFileChangeLog(path).GetChanges()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if got := changeValues(c.Changes); !slices.Equal(got, test.want) {
+					t.Fatalf("expected %v, got %v", test.want, got)
+				}
+			})
+		}
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	err := os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package changelog
+
+import (
+	"time"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+// RecordSetWriter writes the difference between record sets before and after a change to a ChangeLogger
+type RecordSetWriter struct {
+	provider provider.Provider
+	logger   ChangeLogger
+	runID    string
+}
+
+func NewRecordSetWriter(p provider.Provider, logger ChangeLogger) *RecordSetWriter {
+	return &RecordSetWriter{
+		provider: p,
+		logger:   logger,
+		runID:    NewRunID(),
+	}
+}
+
+// RunID returns the identifier written to all changes of the writer
+func (w *RecordSetWriter) RunID() string {
+	return w.runID
+}
+
+// LogChanges logs a change for every value removed from or added to the record set
+func (w *RecordSetWriter) LogChanges(zone string, rec *provider.RecordSet, updated *provider.RecordSet) error {
+	before := rec.Values
+	after := updated.Values
+
+	m := make(map[string]int)
+	for _, x := range before {
+		m[x] = -1
+	}
+	for _, y := range after {
+		m[y] += 1
+	}
+
+	for k, v := range m {
+		if v != 0 {
+			err := w.logChange(zone, rec, updated, k, v)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (w *RecordSetWriter) logChange(zone string, rec *provider.RecordSet, updated *provider.RecordSet, value string, changeValue int) error {
+	action := Remove
+	attributes := rec.Attributes[value]
+	if changeValue == 1 {
+		action = Add
+		attributes = updated.Attributes[value]
+	}
+
+	c := DnsChange{
+		Provider:   w.provider.Name(),
+		Zone:       zone,
+		Record:     rec.Name,
+		RecordType: rec.Type,
+		Value:      value,
+		Action:     action,
+		Attributes: attributes,
+
+		TTL:           rec.TTL,
+		RoutingPolicy: rec.RoutingPolicy,
+		Before:        state(rec.Values),
+		After:         state(updated.Values),
+
+		Timestamp: time.Now().UTC(),
+		RunID:     w.runID,
+	}
+
+	if p, ok := w.provider.(provider.ProjectProvider); ok {
+		c.Project = p.Project()
+	}
+
+	return w.logger.LogChange(c)
+}
+
+// state returns the values of a record set, empty instead of nil so it is not mistaken for a missing state
+func state(values []string) []string {
+	if values == nil {
+		return make([]string, 0)
+	}
+
+	return values
+}
@@ -0,0 +1,185 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package cloudflare
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// max page sizes accepted by the API
+	zonesPageSize   = 50
+	recordsPageSize = 100
+)
+
+type apiClient struct {
+	endpoint   string
+	token      string
+	httpClient *http.Client
+}
+
+type apiResponse struct {
+	Success    bool            `json:"success"`
+	Errors     []apiError      `json:"errors"`
+	Result     json.RawMessage `json:"result"`
+	ResultInfo *resultInfo     `json:"result_info"`
+}
+
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type resultInfo struct {
+	Page       int `json:"page"`
+	TotalPages int `json:"total_pages"`
+}
+
+type zone struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type dnsRecord struct {
+	ID       string  `json:"id,omitempty"`
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	Content  string  `json:"content"`
+	TTL      int64   `json:"ttl"`
+	Proxied  *bool   `json:"proxied,omitempty"`
+	Priority *uint16 `json:"priority,omitempty"`
+	Comment  string  `json:"comment,omitempty"`
+}
+
+type batchRequest struct {
+	Deletes []batchDelete `json:"deletes,omitempty"`
+	Posts   []dnsRecord   `json:"posts,omitempty"`
+}
+
+type batchDelete struct {
+	ID string `json:"id"`
+}
+
+func newApiClient(cfg Config) *apiClient {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	return &apiClient{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		token:      cfg.ApiToken,
+		httpClient: http.DefaultClient,
+	}
+}
+
+func (c *apiClient) listZones(ctx context.Context) ([]zone, error) {
+	zones := make([]zone, 0)
+	err := c.getAll(ctx, "/zones", zonesPageSize, func(b json.RawMessage) error {
+		page := make([]zone, 0)
+		if err := json.Unmarshal(b, &page); err != nil {
+			return err
+		}
+
+		zones = append(zones, page...)
+		return nil
+	})
+
+	return zones, err
+}
+
+func (c *apiClient) listRecords(ctx context.Context, zoneID string) ([]dnsRecord, error) {
+	records := make([]dnsRecord, 0)
+	err := c.getAll(ctx, "/zones/"+url.PathEscape(zoneID)+"/dns_records", recordsPageSize, func(b json.RawMessage) error {
+		page := make([]dnsRecord, 0)
+		if err := json.Unmarshal(b, &page); err != nil {
+			return err
+		}
+
+		records = append(records, page...)
+		return nil
+	})
+
+	return records, err
+}
+
+func (c *apiClient) batch(ctx context.Context, zoneID string, req *batchRequest) error {
+	_, err := c.do(ctx, http.MethodPost, "/zones/"+url.PathEscape(zoneID)+"/dns_records/batch", req)
+	return err
+}
+
+func (c *apiClient) getAll(ctx context.Context, path string, pageSize int, handle func(json.RawMessage) error) error {
+	for page := 1; ; page++ {
+		r, err := c.do(ctx, http.MethodGet, fmt.Sprintf("%s?page=%d&per_page=%d", path, page, pageSize), nil)
+		if err != nil {
+			return err
+		}
+
+		err = handle(r.Result)
+		if err != nil {
+			return err
+		}
+
+		if r.ResultInfo == nil || r.ResultInfo.Page >= r.ResultInfo.TotalPages {
+			return nil
+		}
+	}
+}
+
+func (c *apiClient) do(ctx context.Context, method, path string, body any) (*apiResponse, error) {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	r := &apiResponse{}
+	err = json.NewDecoder(resp.Body).Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: unexpected response (HTTP %d): %w", method, path, resp.StatusCode, err)
+	}
+
+	if !r.Success {
+		return nil, fmt.Errorf("%s %s: %s", method, path, r.errorMessage())
+	}
+
+	return r, nil
+}
+
+func (r *apiResponse) errorMessage() string {
+	msgs := make([]string, 0, len(r.Errors))
+	for _, e := range r.Errors {
+		msgs = append(msgs, fmt.Sprintf("%s (%d)", e.Message, e.Code))
+	}
+
+	return strings.Join(msgs, ", ")
+}
@@ -0,0 +1,12 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package cloudflare
+
+const DefaultEndpoint = "https://api.cloudflare.com/client/v4"
+
+type Config struct {
+	ApiToken string
+	Endpoint string
+}
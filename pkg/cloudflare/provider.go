@@ -0,0 +1,273 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+const (
+	providerName     = "cloudflare"
+	proxiedAttribute = "proxied"
+	commentAttribute = "comment"
+	autoTTL          = 1
+)
+
+// record types with plain content, types using structured data (e.g. SRV, CAA) are not supported
+var supportedTypes = map[string]bool{
+	"A":     true,
+	"AAAA":  true,
+	"CNAME": true,
+	"MX":    true,
+	"NS":    true,
+	"PTR":   true,
+	"TXT":   true,
+}
+
+// CloudflareProvider provides access to zones managed by Cloudflare.
+// Cloudflare stores each value as a separate record, these records are grouped to record sets by name and type.
+type CloudflareProvider struct {
+	client *apiClient
+	zones  *provider.ZoneIDs
+}
+
+func NewProvider(cfg Config) *CloudflareProvider {
+	return &CloudflareProvider{
+		client: newApiClient(cfg),
+		zones:  provider.NewZoneIDs(),
+	}
+}
+
+func (p *CloudflareProvider) Name() string {
+	return providerName
+}
+
+func (p *CloudflareProvider) ListZones(ctx context.Context) ([]string, error) {
+	zones, err := p.client.listZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(zones))
+	for _, z := range zones {
+		p.zones.Add(z.Name, z.ID)
+		names = append(names, z.Name)
+	}
+
+	return names, nil
+}
+
+func (p *CloudflareProvider) ListRecordSets(ctx context.Context, zone string) ([]*provider.RecordSet, error) {
+	id, err := p.zones.Get(ctx, zone, p)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := p.client.listRecords(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupRecords(records), nil
+}
+
+func (p *CloudflareProvider) ApplyChange(ctx context.Context, zone string, change *provider.Change) error {
+	id, err := p.zones.Get(ctx, zone, p)
+	if err != nil {
+		return err
+	}
+
+	req := &batchRequest{}
+
+	existing := make(map[string]bool)
+	if change.Before != nil {
+		for _, r := range rawRecords(change.Before) {
+			existing[recordValue(r)] = true
+		}
+	}
+
+	keep := make(map[string]bool)
+	if change.After != nil {
+		for _, v := range change.After.Values {
+			keep[v] = true
+			if existing[v] {
+				continue
+			}
+
+			r, err := newRecord(change.After, v, change.Before)
+			if err != nil {
+				return err
+			}
+
+			req.Posts = append(req.Posts, r)
+		}
+	}
+
+	if change.Before != nil {
+		for _, r := range rawRecords(change.Before) {
+			if !keep[recordValue(r)] {
+				req.Deletes = append(req.Deletes, batchDelete{ID: r.ID})
+			}
+		}
+	}
+
+	return p.client.batch(ctx, id, req)
+}
+
+type recordKey struct {
+	name       string
+	recordType string
+}
+
+func groupRecords(records []dnsRecord) []*provider.RecordSet {
+	recs := make([]*provider.RecordSet, 0)
+	index := make(map[recordKey]*provider.RecordSet)
+	skipped := make(map[recordKey]bool)
+
+	for _, r := range records {
+		key := recordKey{name: r.Name, recordType: r.Type}
+
+		if !supportedTypes[r.Type] {
+			if !skipped[key] {
+				log.Printf("WARN - %s %s: record type not supported, skipping\n", r.Type, r.Name)
+				skipped[key] = true
+			}
+			continue
+		}
+
+		rec, found := index[key]
+		if !found {
+			rec = &provider.RecordSet{
+				Name:       r.Name,
+				Type:       r.Type,
+				TTL:        r.TTL,
+				Values:     make([]string, 0),
+				Attributes: make(map[string]map[string]string),
+				Raw:        make([]dnsRecord, 0),
+			}
+			index[key] = rec
+			recs = append(recs, rec)
+		}
+
+		value := recordValue(r)
+		rec.Values = append(rec.Values, value)
+		rec.Raw = append(rec.Raw.([]dnsRecord), r)
+
+		attributes := make(map[string]string)
+		if r.Proxied != nil {
+			attributes[proxiedAttribute] = strconv.FormatBool(*r.Proxied)
+		}
+
+		if r.Comment != "" {
+			attributes[commentAttribute] = r.Comment
+		}
+
+		if len(attributes) > 0 {
+			rec.Attributes[value] = attributes
+		}
+	}
+
+	return recs
+}
+
+func rawRecords(rec *provider.RecordSet) []dnsRecord {
+	if raw, ok := rec.Raw.([]dnsRecord); ok {
+		return raw
+	}
+
+	return nil
+}
+
+func recordValue(r dnsRecord) string {
+	if r.Type == "MX" && r.Priority != nil {
+		return fmt.Sprintf("%d %s", *r.Priority, r.Content)
+	}
+
+	return r.Content
+}
+
+func newRecord(rec *provider.RecordSet, value string, before *provider.RecordSet) (dnsRecord, error) {
+	r := dnsRecord{
+		Name:    rec.Name,
+		Type:    rec.Type,
+		Content: value,
+		TTL:     rec.TTL,
+	}
+
+	if r.TTL <= 0 {
+		r.TTL = autoTTL
+	}
+
+	if rec.Type == "MX" {
+		prio, host, found := strings.Cut(value, " ")
+		if !found {
+			return r, fmt.Errorf("invalid MX value %q, expected priority and host", value)
+		}
+
+		p, err := strconv.ParseUint(prio, 10, 16)
+		if err != nil {
+			return r, fmt.Errorf("invalid MX priority in value %q: %w", value, err)
+		}
+
+		priority := uint16(p)
+		r.Priority = &priority
+		r.Content = host
+	}
+
+	r.Proxied = proxiedFlag(rec, value, before)
+	r.Comment = comment(rec, value, before)
+
+	return r, nil
+}
+
+// proxiedFlag returns the proxied flag recorded for the value. If the value is new (e.g. replacement)
+// the flag of the existing records is inherited.
+func proxiedFlag(rec *provider.RecordSet, value string, before *provider.RecordSet) *bool {
+	if s, found := rec.Attributes[value][proxiedAttribute]; found {
+		proxied, err := strconv.ParseBool(s)
+		if err == nil {
+			return &proxied
+		}
+	}
+
+	if before == nil {
+		return nil
+	}
+
+	for _, r := range rawRecords(before) {
+		if r.Proxied != nil {
+			return r.Proxied
+		}
+	}
+
+	return nil
+}
+
+// comment returns the comment recorded for the value. If the value is new (e.g. replacement)
+// the comment of a record replaced by the change is inherited.
+func comment(rec *provider.RecordSet, value string, before *provider.RecordSet) string {
+	if c, found := rec.Attributes[value][commentAttribute]; found {
+		return c
+	}
+
+	if before == nil {
+		return ""
+	}
+
+	for _, r := range rawRecords(before) {
+		if r.Comment != "" && !slices.Contains(rec.Values, recordValue(r)) {
+			return r.Comment
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,207 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package cloudflare
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+// standIn answers the Cloudflare API calls used by the provider. Lists are returned one item per page.
+type standIn struct {
+	mutex   sync.Mutex
+	zones   []zone
+	records map[string][]dnsRecord
+	batches map[string]batchRequest
+}
+
+func (s *standIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer test" {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"success":false,"errors":[{"code":9109,"message":"Invalid access token"}]}`)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/zones":
+		writePage(w, r, s.zones)
+	case r.Method == http.MethodGet && r.PathValue("zone") != "":
+		writePage(w, r, s.records[r.PathValue("zone")])
+	case r.Method == http.MethodPost && r.PathValue("zone") != "":
+		req := batchRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"success":false,"errors":[{"code":1000,"message":%q}]}`, err.Error())
+			return
+		}
+
+		s.batches[r.PathValue("zone")] = req
+		fmt.Fprint(w, `{"success":true,"errors":[],"result":{}}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"success":false,"errors":[{"code":7003,"message":"Could not route"}]}`)
+	}
+}
+
+func writePage[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+	result := []T{}
+	if page >= 1 && page <= len(items) {
+		result = items[page-1 : page]
+	}
+
+	b, _ := json.Marshal(result)
+	fmt.Fprintf(w, `{"success":true,"errors":[],"result":%s,"result_info":{"page":%d,"total_pages":%d}}`, b, page, len(items))
+}
+
+func newTestProvider(t *testing.T) (*CloudflareProvider, *standIn) {
+	t.Helper()
+
+	proxied := true
+	prio := uint16(10)
+
+	s := &standIn{
+		zones: []zone{{ID: "z1", Name: "example.com"}, {ID: "z2", Name: "example.org"}},
+		records: map[string][]dnsRecord{
+			"z1": {
+				{ID: "r1", Name: "www.example.com", Type: "A", Content: "10.0.0.1", TTL: 1, Proxied: &proxied, Comment: "web 1"},
+				{ID: "r2", Name: "www.example.com", Type: "A", Content: "10.0.0.2", TTL: 1, Proxied: &proxied},
+				{ID: "r3", Name: "example.com", Type: "MX", Content: "mx1.example.com", TTL: 300, Priority: &prio},
+				{ID: "r4", Name: "_sip._udp.example.com", Type: "SRV", TTL: 300},
+			},
+		},
+		batches: make(map[string]batchRequest),
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/zones", s)
+	mux.Handle("/zones/{zone}/dns_records", s)
+	mux.Handle("/zones/{zone}/dns_records/batch", s)
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return NewProvider(Config{ApiToken: "test", Endpoint: srv.URL}), s
+}
+
+func TestListZones(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	zones, err := p.ListZones(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(zones, []string{"example.com", "example.org"}) {
+		t.Fatalf("unexpected zones %v", zones)
+	}
+}
+
+func TestListRecordSets(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	recs, err := p.ListRecordSets(context.Background(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recs) != 2 {
+		t.Fatalf("expected SRV record to be skipped, got %d record sets", len(recs))
+	}
+
+	www := provider.FindRecordSet("www.example.com", "A", recs)
+	if www == nil || !slices.Equal(www.Values, []string{"10.0.0.1", "10.0.0.2"}) {
+		t.Fatalf("unexpected record set %+v", www)
+	}
+
+	if a := www.Attributes["10.0.0.1"]; a[proxiedAttribute] != "true" || a[commentAttribute] != "web 1" {
+		t.Fatalf("unexpected attributes %v", a)
+	}
+
+	mx := provider.FindRecordSet("example.com", "MX", recs)
+	if mx == nil || !slices.Equal(mx.Values, []string{"10 mx1.example.com"}) {
+		t.Fatalf("unexpected record set %+v", mx)
+	}
+}
+
+func TestApplyChange(t *testing.T) {
+	p, s := newTestProvider(t)
+
+	recs, err := p.ListRecordSets(context.Background(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	www := provider.FindRecordSet("www.example.com", "A", recs)
+	err = p.ApplyChange(context.Background(), "example.com", &provider.Change{
+		Before: www,
+		After:  www.WithValues([]string{"10.0.0.2", "10.0.0.3"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := s.batches["z1"]
+	if len(req.Deletes) != 1 || req.Deletes[0].ID != "r1" {
+		t.Fatalf("expected record r1 to be deleted, got %+v", req.Deletes)
+	}
+
+	if len(req.Posts) != 1 {
+		t.Fatalf("expected 1 record to be created, got %+v", req.Posts)
+	}
+
+	created := req.Posts[0]
+	if created.Content != "10.0.0.3" || created.Proxied == nil || !*created.Proxied || created.Comment != "web 1" {
+		t.Fatalf("unexpected record created %+v", created)
+	}
+}
+
+func TestApplyChangeRestoresComment(t *testing.T) {
+	p, s := newTestProvider(t)
+	s.records["z1"] = s.records["z1"][1:]
+
+	recs, err := p.ListRecordSets(context.Background(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// attributes of the drained value as restored from the changelog
+	before := provider.FindRecordSet("www.example.com", "A", recs)
+	after := before.WithValues([]string{"10.0.0.2", "10.0.0.1"})
+	after.Attributes = map[string]map[string]string{"10.0.0.1": {proxiedAttribute: "false", commentAttribute: "web 1"}}
+
+	err = p.ApplyChange(context.Background(), "example.com", &provider.Change{Before: before, After: after})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := s.batches["z1"]
+	if len(req.Posts) != 1 || req.Posts[0].Comment != "web 1" || req.Posts[0].Proxied == nil || *req.Posts[0].Proxied {
+		t.Fatalf("unexpected records created %+v", req.Posts)
+	}
+}
+
+func TestInvalidToken(t *testing.T) {
+	p, _ := newTestProvider(t)
+	p.client.token = "wrong"
+
+	_, err := p.ListZones(context.Background())
+	if err == nil {
+		t.Fatal("expected error for invalid token")
+	}
+}
@@ -0,0 +1,295 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package drain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"time"
+
+	"github.com/czerwonk/dns-drain/pkg/changelog"
+	"github.com/czerwonk/dns-drain/pkg/provider"
+	"github.com/czerwonk/dns-drain/pkg/result"
+	"github.com/czerwonk/dns-drain/pkg/spf"
+	"github.com/czerwonk/dns-drain/pkg/worker"
+)
+
+// DnsDrainer implements the drain logic independent of the DNS backend
+type DnsDrainer struct {
+	provider provider.Provider
+	updater  *provider.Updater
+	writer   *changelog.RecordSetWriter
+	opt      *Options
+}
+
+func NewDrainer(p provider.Provider, logger changelog.ChangeLogger, opt *Options) *DnsDrainer {
+	return &DnsDrainer{
+		provider: p,
+		updater:  provider.NewUpdater(p, opt.DryRun, opt.Limit),
+		writer:   changelog.NewRecordSetWriter(p, logger),
+		opt:      opt,
+	}
+}
+
+// RunID returns the identifier written to all changes of the drainer
+func (d *DnsDrainer) RunID() string {
+	return d.writer.RunID()
+}
+
+func (d *DnsDrainer) DrainWithIpNet(ctx context.Context, ipNet *net.IPNet, newIp net.IP) (*result.Result, error) {
+	filter := func(rec *provider.RecordSet) []string {
+		return filterWithIpNet(rec, ipNet)
+	}
+
+	newValue := ""
+	if newIp != nil {
+		newValue = newIp.String()
+	}
+
+	replacer := func(rec *provider.RecordSet, removed string) string {
+		if spf.IsRecordType(rec.Type) {
+			return spf.Drain(removed, ipNet, newIp)
+		}
+
+		return newValue
+	}
+
+	return d.performForZones(ctx, filter, replacer)
+}
+
+func (d *DnsDrainer) DrainWithValue(ctx context.Context, value string, newValue string) (*result.Result, error) {
+	filter := func(rec *provider.RecordSet) []string {
+		return filterWithValue(rec, value)
+	}
+
+	replacer := func(rec *provider.RecordSet, removed string) string {
+		return replaceValue(rec, removed, value, newValue)
+	}
+
+	return d.performForZones(ctx, filter, replacer)
+}
+
+func (d *DnsDrainer) DrainWithRegex(ctx context.Context, regex *regexp.Regexp, newValue string) (*result.Result, error) {
+	filter := func(rec *provider.RecordSet) []string {
+		return filterWithRegex(rec, regex)
+	}
+
+	return d.performForZones(ctx, filter, replaceWith(newValue))
+}
+
+// replaceWith returns a replacer replacing all removed values by the same value
+func replaceWith(newValue string) Replacer {
+	return func(*provider.RecordSet, string) string {
+		return newValue
+	}
+}
+
+func (d *DnsDrainer) performForZones(ctx context.Context, filter Filter, replacer Replacer) (*result.Result, error) {
+	log.Printf("Starting drain run %s\n", d.writer.RunID())
+	res := result.NewResult()
+
+	zones, err := selectZones(ctx, d.provider, d.opt)
+	if err != nil {
+		return res, err
+	}
+
+	pool := worker.NewPool(d.opt.Parallelism)
+	for _, z := range zones {
+		pool.Go(func() {
+			d.drainForZone(ctx, z, filter, replacer, res)
+		})
+	}
+	pool.Wait()
+
+	if d.opt.Wait && !d.opt.DryRun && ctx.Err() == nil {
+		waitForChanges(ctx, d.provider, d.opt.WaitTimeout, res)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return res, fmt.Errorf("drain was interrupted: %w", err)
+	}
+
+	return res, nil
+}
+
+// selectZones returns the zones of the provider matching the zone and skip filters
+func selectZones(ctx context.Context, p provider.Provider, opt *Options) ([]string, error) {
+	all, err := p.ListZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	zones := make([]string, 0)
+	for _, z := range all {
+		if opt.matchesZone(z) {
+			zones = append(zones, z)
+		}
+	}
+
+	return zones, nil
+}
+
+func (d *DnsDrainer) drainForZone(ctx context.Context, zone string, filter Filter, replacer Replacer, res *result.Result) {
+	recs, err := d.provider.ListRecordSets(ctx, zone)
+	if err != nil {
+		log.Printf("ERROR - %s: %s\n", zone, err)
+		res.AddZoneFailure(zone, err)
+		return
+	}
+
+	res.AddZone(len(recs))
+	log.Printf("%s: %d record sets\n", zone, len(recs))
+
+	var batch *zoneBatch
+	if d.opt.Batch {
+		batch = d.newZoneBatch(zone)
+	}
+
+	for _, rec := range recs {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !d.opt.matchesName(rec.Name) {
+			continue
+		}
+
+		d.handleRecordSet(ctx, zone, rec, filter, replacer, batch, res)
+	}
+
+	if batch != nil && ctx.Err() == nil {
+		batch.commit(ctx, res)
+	}
+}
+
+func (d *DnsDrainer) newZoneBatch(zone string) *zoneBatch {
+	return &zoneBatch{
+		zone:    zone,
+		batch:   d.updater.NewBatch(zone),
+		writer:  d.writer,
+		pending: make([]pendingUpdate, 0),
+	}
+}
+
+// handleRecordSet removes the matching values from the record set. If batch is set, the change is added to the batch instead of being applied.
+func (d *DnsDrainer) handleRecordSet(ctx context.Context, zone string, rec *provider.RecordSet, filter Filter, replacer Replacer, batch *zoneBatch, res *result.Result) {
+	if !d.opt.matchesType(rec.Type) {
+		return
+	}
+
+	values := filter(rec)
+
+	if len(values) == len(rec.Values) {
+		return
+	}
+
+	replacements := getReplacements(rec, values, replacer)
+	if !hasEnabledValues(rec, values) && len(replacements) == 0 && !d.opt.Force {
+		log.Printf("WARN - %s %s: Only one value assigned to record. Can not drain!\n", rec.Type, rec.Name)
+		res.AddSkipped()
+		return
+	}
+
+	if !d.opt.Force && drainsLastSPFMechanism(rec, values, replacer) {
+		log.Printf("WARN - %s %s: No ip4/ip6 mechanism would be left in SPF policy. Can not drain!\n", rec.Type, rec.Name)
+		res.AddSkipped()
+		return
+	}
+
+	for _, r := range replacements {
+		if !isInValues(r, values) {
+			values = append(values, r)
+		}
+	}
+
+	var done bool
+	var err error
+	if batch != nil {
+		err = batch.add(ctx, rec, values)
+	} else {
+		done, err = d.updateRecordSet(ctx, rec, zone, values)
+	}
+
+	switch {
+	case errors.Is(err, provider.ErrLimitReached):
+		res.AddSkipped()
+	case err != nil && ctx.Err() != nil:
+		return
+	case err != nil:
+		log.Printf("ERROR - %s: %s", rec.Name, err)
+		res.AddRecordFailure(zone, rec.Name, rec.Type, err)
+	case done:
+		res.AddChanged()
+	}
+}
+
+// hasEnabledValues checks if any of the values is served by the DNS server
+func hasEnabledValues(rec *provider.RecordSet, values []string) bool {
+	for _, v := range values {
+		if !rec.IsDisabled(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// drainsLastSPFMechanism checks if a SPF policy would lose all of its ip4 and ip6 mechanisms
+func drainsLastSPFMechanism(rec *provider.RecordSet, kept []string, replacer Replacer) bool {
+	if !spf.IsRecordType(rec.Type) {
+		return false
+	}
+
+	for _, v := range rec.Values {
+		if !isInValues(v, kept) && spf.RemovesAllMechanisms(v, replacer(rec, v)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getReplacements returns the values replacing the values removed from the record set
+func getReplacements(rec *provider.RecordSet, kept []string, replacer Replacer) []string {
+	res := make([]string, 0)
+	for _, v := range rec.Values {
+		if isInValues(v, kept) {
+			continue
+		}
+
+		r := replacer(rec, v)
+		if len(r) > 0 && !isInValues(r, res) {
+			res = append(res, r)
+		}
+	}
+
+	return res
+}
+
+func (d *DnsDrainer) updateRecordSet(ctx context.Context, rec *provider.RecordSet, zone string, values []string) (bool, error) {
+	updated := rec.WithValues(values)
+	done, err := d.updater.UpdateRecordSet(ctx, zone, rec, updated)
+	if err != nil {
+		return false, err
+	}
+
+	if done {
+		return true, d.writer.LogChanges(zone, rec, updated)
+	}
+
+	return false, nil
+}
+
+// waitForChanges waits for the changes to complete. Changes not completed are reported as failures.
+func waitForChanges(ctx context.Context, p provider.Provider, timeout time.Duration, res *result.Result) {
+	for _, c := range provider.WaitForChanges(ctx, p, timeout) {
+		log.Printf("ERROR - %s %s: %s\n", c.RecordType, c.Record, c.Err)
+		res.AddRecordFailure(c.Zone, c.Record, c.RecordType, c.Err)
+	}
+}
@@ -5,12 +5,15 @@
 package drain
 
 import (
+	"context"
 	"net"
 	"regexp"
+
+	"github.com/czerwonk/dns-drain/pkg/result"
 )
 
 type Drainer interface {
-	DrainWithIpNet(ipNet *net.IPNet, newIp net.IP) error
-	DrainWithValue(value string, newValue string) error
-	DrainWithRegex(regex *regexp.Regexp, newValue string) error
+	DrainWithIpNet(ctx context.Context, ipNet *net.IPNet, newIp net.IP) (*result.Result, error)
+	DrainWithValue(ctx context.Context, value string, newValue string) (*result.Result, error)
+	DrainWithRegex(ctx context.Context, regex *regexp.Regexp, newValue string) (*result.Result, error)
 }
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package drain
+
+import (
+	"net"
+	"regexp"
+	"slices"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+	"github.com/czerwonk/dns-drain/pkg/spf"
+)
+
+// Filter returns the values of the record set which should be kept
+type Filter func(*provider.RecordSet) []string
+
+// Replacer returns the value replacing a value removed from the record set (empty = no replacement)
+type Replacer func(rec *provider.RecordSet, removed string) string
+
+func filterWithRegex(rec *provider.RecordSet, regex *regexp.Regexp) []string {
+	res := make([]string, 0)
+
+	for _, x := range rec.Values {
+		if !regex.MatchString(x) {
+			res = append(res, x)
+		}
+	}
+
+	return res
+}
+
+// filterWithValue removes the value. For record types pointing to other hosts (e.g. MX, SRV, CNAME) values with the value as target are removed as well.
+func filterWithValue(rec *provider.RecordSet, value string) []string {
+	res := make([]string, 0)
+
+	for _, x := range rec.Values {
+		if x != value && !matchesTarget(rec.Type, x, value) {
+			res = append(res, x)
+		}
+	}
+
+	return res
+}
+
+// replaceValue returns the replacement for a value removed by filterWithValue. If only the target matched, the target is replaced.
+func replaceValue(rec *provider.RecordSet, removed, value, newValue string) string {
+	if removed == value || len(newValue) == 0 {
+		return newValue
+	}
+
+	if r, ok := replaceTarget(rec.Type, removed, newValue); ok {
+		return r
+	}
+
+	return newValue
+}
+
+// filterWithIpNet removes the IPs in the network. For TXT and SPF records SPF policies with ip4 or ip6 mechanisms in the network are removed.
+func filterWithIpNet(rec *provider.RecordSet, ipNet *net.IPNet) []string {
+	res := make([]string, 0)
+
+	for _, x := range rec.Values {
+		if spf.IsRecordType(rec.Type) {
+			if !spf.Matches(x, ipNet) {
+				res = append(res, x)
+			}
+			continue
+		}
+
+		ip := net.ParseIP(x)
+		if ip == nil || !ipNet.Contains(ip) {
+			res = append(res, x)
+		}
+	}
+
+	return res
+}
+
+func isInValues(value string, values []string) bool {
+	return slices.Contains(values, value)
+}
@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package drain
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+	"github.com/czerwonk/dns-drain/pkg/result"
+	"github.com/czerwonk/dns-drain/pkg/worker"
+)
+
+// Match is a record set containing values matched by a search
+type Match struct {
+	Zone    string   `json:"zone"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	TTL     int64    `json:"ttl"`
+	Values  []string `json:"values"`
+	Matched []string `json:"matched"`
+}
+
+// Finder searches record sets using the same matching and filters as the drainer without changing anything
+type Finder struct {
+	provider provider.Provider
+	opt      *Options
+}
+
+func NewFinder(p provider.Provider, opt *Options) *Finder {
+	return &Finder{
+		provider: p,
+		opt:      opt,
+	}
+}
+
+func (f *Finder) FindWithIpNet(ctx context.Context, ipNet *net.IPNet) ([]*Match, *result.Result, error) {
+	return f.find(ctx, func(rec *provider.RecordSet) []string {
+		return filterWithIpNet(rec, ipNet)
+	})
+}
+
+func (f *Finder) FindWithValue(ctx context.Context, value string) ([]*Match, *result.Result, error) {
+	return f.find(ctx, func(rec *provider.RecordSet) []string {
+		return filterWithValue(rec, value)
+	})
+}
+
+func (f *Finder) FindWithRegex(ctx context.Context, regex *regexp.Regexp) ([]*Match, *result.Result, error) {
+	return f.find(ctx, func(rec *provider.RecordSet) []string {
+		return filterWithRegex(rec, regex)
+	})
+}
+
+func (f *Finder) find(ctx context.Context, filter Filter) ([]*Match, *result.Result, error) {
+	res := result.NewResult()
+
+	zones, err := selectZones(ctx, f.provider, f.opt)
+	if err != nil {
+		return nil, res, err
+	}
+
+	mutex := sync.Mutex{}
+	matches := make([]*Match, 0)
+
+	pool := worker.NewPool(f.opt.Parallelism)
+	for _, z := range zones {
+		pool.Go(func() {
+			m := f.findInZone(ctx, z, filter, res)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			matches = append(matches, m...)
+		})
+	}
+	pool.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, res, fmt.Errorf("search was interrupted: %w", err)
+	}
+
+	slices.SortFunc(matches, compareMatches)
+	return matches, res, nil
+}
+
+func (f *Finder) findInZone(ctx context.Context, zone string, filter Filter, res *result.Result) []*Match {
+	recs, err := f.provider.ListRecordSets(ctx, zone)
+	if err != nil {
+		log.Printf("ERROR - %s: %s\n", zone, err)
+		res.AddZoneFailure(zone, err)
+		return nil
+	}
+
+	res.AddZone(len(recs))
+
+	matches := make([]*Match, 0)
+	for _, rec := range recs {
+		if !f.opt.matchesRecordSet(rec) {
+			continue
+		}
+
+		kept := filter(rec)
+		if len(kept) == len(rec.Values) {
+			continue
+		}
+
+		matched := make([]string, 0)
+		for _, v := range rec.Values {
+			if !isInValues(v, kept) {
+				matched = append(matched, v)
+			}
+		}
+
+		matches = append(matches, &Match{
+			Zone:    zone,
+			Name:    rec.Name,
+			Type:    rec.Type,
+			TTL:     rec.TTL,
+			Values:  rec.Values,
+			Matched: matched,
+		})
+	}
+
+	return matches
+}
+
+func compareMatches(a, b *Match) int {
+	if a.Zone != b.Zone {
+		return strings.Compare(a.Zone, b.Zone)
+	}
+
+	if a.Name != b.Name {
+		return strings.Compare(a.Name, b.Name)
+	}
+
+	return strings.Compare(a.Type, b.Type)
+}
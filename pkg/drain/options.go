@@ -4,14 +4,43 @@
 
 package drain
 
-import "regexp"
+import (
+	"regexp"
+	"time"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
 
 type Options struct {
-	DryRun     bool
-	Force      bool
-	ZoneFilter *regexp.Regexp
-	SkipFilter *regexp.Regexp
-	NameFilter *regexp.Regexp
-	TypeFilter string
-	Limit      int64
+	DryRun      bool
+	Force       bool
+	ZoneFilter  *regexp.Regexp
+	SkipFilter  *regexp.Regexp
+	NameFilter  *regexp.Regexp
+	TypeFilter  string
+	Limit       int64
+	Wait        bool
+	WaitTimeout time.Duration
+	Parallelism int
+	Batch       bool
+}
+
+func (o *Options) matchesZone(zone string) bool {
+	if o.SkipFilter != nil && o.SkipFilter.MatchString(zone) {
+		return false
+	}
+
+	return o.ZoneFilter == nil || o.ZoneFilter.MatchString(zone)
+}
+
+func (o *Options) matchesName(name string) bool {
+	return o.NameFilter == nil || o.NameFilter.MatchString(name)
+}
+
+func (o *Options) matchesType(recordType string) bool {
+	return len(o.TypeFilter) == 0 || o.TypeFilter == recordType
+}
+
+func (o *Options) matchesRecordSet(rec *provider.RecordSet) bool {
+	return o.matchesName(rec.Name) && o.matchesType(rec.Type)
 }
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package drain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/czerwonk/dns-drain/pkg/changelog"
+	"github.com/czerwonk/dns-drain/pkg/plan"
+	"github.com/czerwonk/dns-drain/pkg/provider"
+	"github.com/czerwonk/dns-drain/pkg/result"
+	"github.com/czerwonk/dns-drain/pkg/worker"
+)
+
+// ErrPlanOutdated is reported for records changed since the plan was created
+var ErrPlanOutdated = errors.New("live record set does not match the state the plan was created from")
+
+// PlanApplier applies the changes of a plan exactly as planned
+type PlanApplier struct {
+	provider    provider.Provider
+	updater     *provider.Updater
+	writer      *changelog.RecordSetWriter
+	parallelism int
+}
+
+// NewPlanApplier returns an applier processing up to parallelism zones concurrently (< 1 = unlimited)
+func NewPlanApplier(p provider.Provider, logger changelog.ChangeLogger, parallelism int) *PlanApplier {
+	return &PlanApplier{
+		provider:    p,
+		updater:     provider.NewUpdater(p, false, -1),
+		writer:      changelog.NewRecordSetWriter(p, logger),
+		parallelism: parallelism,
+	}
+}
+
+// Apply executes the changes of the plan. Changes of record sets modified since the plan was created are refused.
+func (a *PlanApplier) Apply(ctx context.Context, pl *plan.Plan) (*result.Result, error) {
+	res := result.NewResult()
+
+	if pl.Provider != a.provider.Name() {
+		return res, fmt.Errorf("plan was created for provider %s, not %s", pl.Provider, a.provider.Name())
+	}
+
+	if p, ok := a.provider.(provider.ProjectProvider); ok && pl.Project != p.Project() {
+		return res, fmt.Errorf("plan was created for project %s, not %s", pl.Project, p.Project())
+	}
+
+	log.Printf("Applying plan with %d changes (run %s)\n", len(pl.Changes), a.writer.RunID())
+
+	pool := worker.NewPool(a.parallelism)
+	for z, c := range pl.GroupByZone() {
+		pool.Go(func() {
+			a.applyForZone(ctx, z, c, res)
+		})
+	}
+	pool.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return res, fmt.Errorf("apply was interrupted: %w", err)
+	}
+
+	return res, nil
+}
+
+func (a *PlanApplier) applyForZone(ctx context.Context, zone string, changes []*plan.Change, res *result.Result) {
+	recs, err := a.provider.ListRecordSets(ctx, zone)
+	if err != nil {
+		log.Printf("ERROR - %s: %s\n", zone, err)
+		res.AddZoneFailure(zone, err)
+		return
+	}
+
+	res.AddZone(len(recs))
+
+	for _, c := range changes {
+		if ctx.Err() != nil {
+			return
+		}
+
+		done, err := a.applyChange(ctx, zone, c, recs)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return
+		case err != nil:
+			log.Printf("ERROR - %s %s: %s\n", c.RecordType, c.Record, err)
+			res.AddRecordFailure(zone, c.Record, c.RecordType, err)
+		case done:
+			res.AddChanged()
+		}
+	}
+}
+
+func (a *PlanApplier) applyChange(ctx context.Context, zone string, c *plan.Change, recs []*provider.RecordSet) (bool, error) {
+	rec := provider.FindRecordSet(c.Record, c.RecordType, recs)
+	if !c.Matches(rec) {
+		return false, ErrPlanOutdated
+	}
+
+	if rec == nil {
+		rec = &provider.RecordSet{
+			Name:          c.Record,
+			Type:          c.RecordType,
+			TTL:           c.TTL,
+			RoutingPolicy: c.RoutingPolicy,
+			Values:        make([]string, 0),
+		}
+	}
+
+	updated := rec.WithValues(c.After)
+	done, err := a.updater.UpdateRecordSet(ctx, zone, rec, updated)
+	if err != nil || !done {
+		return false, err
+	}
+
+	return true, a.writer.LogChanges(zone, rec, updated)
+}
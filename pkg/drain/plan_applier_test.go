@@ -0,0 +1,154 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package drain
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/czerwonk/dns-drain/pkg/changelog"
+	"github.com/czerwonk/dns-drain/pkg/plan"
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+// memoryProvider holds the record sets of a single zone in memory
+type memoryProvider struct {
+	mutex sync.Mutex
+	zone  string
+	recs  []*provider.RecordSet
+}
+
+func (p *memoryProvider) Name() string {
+	return "memory"
+}
+
+func (p *memoryProvider) ListZones(context.Context) ([]string, error) {
+	return []string{p.zone}, nil
+}
+
+func (p *memoryProvider) ListRecordSets(context.Context, string) ([]*provider.RecordSet, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	recs := make([]*provider.RecordSet, 0, len(p.recs))
+	for _, r := range p.recs {
+		recs = append(recs, r.WithValues(slices.Clone(r.Values)))
+	}
+
+	return recs, nil
+}
+
+func (p *memoryProvider) ApplyChange(_ context.Context, _ string, change *provider.Change) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	rec := change.After
+	if rec == nil {
+		rec = change.Before
+	}
+
+	p.recs = slices.DeleteFunc(p.recs, func(r *provider.RecordSet) bool {
+		return r.Name == rec.Name && r.Type == rec.Type
+	})
+
+	if change.After != nil {
+		p.recs = append(p.recs, change.After)
+	}
+
+	return nil
+}
+
+func (p *memoryProvider) values(name string) []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, r := range p.recs {
+		if r.Name == name {
+			return r.Values
+		}
+	}
+
+	return nil
+}
+
+// memoryLogger keeps the logged changes in memory
+type memoryLogger struct {
+	mutex   sync.Mutex
+	changes []changelog.DnsChange
+}
+
+func (l *memoryLogger) LogChange(c changelog.DnsChange) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.changes = append(l.changes, c)
+	return nil
+}
+
+func TestPlanApplier(t *testing.T) {
+	p := &memoryProvider{
+		zone: "example.com.",
+		recs: []*provider.RecordSet{
+			{Name: "www.example.com.", Type: "A", TTL: 300, Values: []string{"10.0.0.1", "10.0.0.2"}},
+			{Name: "api.example.com.", Type: "A", TTL: 300, Values: []string{"10.0.0.1", "10.0.0.3", "10.0.0.4"}},
+		},
+	}
+
+	pl := &plan.Plan{
+		Provider: "memory",
+		Changes: []*plan.Change{
+			{Zone: "example.com.", Record: "www.example.com.", RecordType: "A", TTL: 300,
+				Before: []string{"10.0.0.1", "10.0.0.2"}, After: []string{"10.0.0.2"}},
+			{Zone: "example.com.", Record: "api.example.com.", RecordType: "A", TTL: 300,
+				Before: []string{"10.0.0.1", "10.0.0.3"}, After: []string{"10.0.0.3"}},
+			{Zone: "example.com.", Record: "mail.example.com.", RecordType: "A", TTL: 60,
+				Before: []string{}, After: []string{"10.0.0.5"}},
+		},
+	}
+
+	logger := &memoryLogger{}
+	res, err := NewPlanApplier(p, logger, 1).Apply(context.Background(), pl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.RecordsChanged != 2 || res.RecordsFailed != 1 {
+		t.Fatalf("unexpected result %s", res)
+	}
+
+	if len(res.Failures) != 1 || res.Failures[0].Record != "api.example.com." || res.Failures[0].Error != ErrPlanOutdated.Error() {
+		t.Fatalf("expected change of drifted record set to be refused, got %+v", res.Failures)
+	}
+
+	tests := []struct {
+		record string
+		want   []string
+	}{
+		{record: "www.example.com.", want: []string{"10.0.0.2"}},
+		{record: "api.example.com.", want: []string{"10.0.0.1", "10.0.0.3", "10.0.0.4"}},
+		{record: "mail.example.com.", want: []string{"10.0.0.5"}},
+	}
+
+	for _, test := range tests {
+		if got := p.values(test.record); !slices.Equal(got, test.want) {
+			t.Fatalf("%s: expected %v, got %v", test.record, test.want, got)
+		}
+	}
+
+	if len(logger.changes) != 2 {
+		t.Fatalf("expected 2 changes to be logged, got %d", len(logger.changes))
+	}
+}
+
+func TestPlanApplierOtherProvider(t *testing.T) {
+	p := &memoryProvider{zone: "example.com."}
+
+	_, err := NewPlanApplier(p, &memoryLogger{}, 1).Apply(context.Background(), &plan.Plan{Provider: "gcloud"})
+	if err == nil {
+		t.Fatal("expected plan of other provider to be refused")
+	}
+}
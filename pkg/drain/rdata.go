@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package drain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// targetField returns the index of the field containing the host name a record of the type points to (-1 = none)
+func targetField(recordType string) int {
+	switch recordType {
+	case "CNAME", "DNAME", "NS", "PTR":
+		return 0
+	case "MX", "KX", "AFSDB", "RT", "SVCB", "HTTPS":
+		return 1
+	case "SRV":
+		return 3
+	default:
+		return -1
+	}
+}
+
+// fieldSpan returns the position of the field with the given index in the whitespace separated value
+func fieldSpan(value string, idx int) (start int, end int, found bool) {
+	field := -1
+	inField := false
+	for i, r := range value {
+		if unicode.IsSpace(r) {
+			if inField && field == idx {
+				return start, i, true
+			}
+
+			inField = false
+			continue
+		}
+
+		if !inField {
+			inField = true
+			field++
+			start = i
+		}
+	}
+
+	if inField && field == idx {
+		return start, len(value), true
+	}
+
+	return 0, 0, false
+}
+
+// target returns the host name the value of a record of the type points to
+func target(recordType, value string) (string, bool) {
+	idx := targetField(recordType)
+	if idx < 0 {
+		return "", false
+	}
+
+	start, end, found := fieldSpan(value, idx)
+	if !found {
+		return "", false
+	}
+
+	return value[start:end], true
+}
+
+// matchesTarget checks if the value of a record of the type points to the host name
+func matchesTarget(recordType, value, host string) bool {
+	t, found := target(recordType, value)
+	return found && sameHost(t, host)
+}
+
+// replaceTarget replaces the host name in the value keeping all other fields (e.g. priority, weight and port).
+// The host name is written with or without trailing dot like the replaced one.
+func replaceTarget(recordType, value, host string) (string, bool) {
+	idx := targetField(recordType)
+	if idx < 0 {
+		return "", false
+	}
+
+	start, end, found := fieldSpan(value, idx)
+	if !found {
+		return "", false
+	}
+
+	host = strings.TrimSuffix(host, ".")
+	if strings.HasSuffix(value[start:end], ".") {
+		host += "."
+	}
+
+	return value[:start] + host + value[end:], true
+}
+
+func sameHost(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "."), strings.TrimSuffix(b, "."))
+}
@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package drain
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+func TestMatchesTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		recordType string
+		value      string
+		host       string
+		want       bool
+	}{
+		{
+			name:       "MX",
+			recordType: "MX",
+			value:      "10 mx1.example.com.",
+			host:       "mx1.example.com.",
+			want:       true,
+		},
+		{
+			name:       "trailing dot and case ignored",
+			recordType: "CNAME",
+			value:      "MX1.example.com.",
+			host:       "mx1.example.com",
+			want:       true,
+		},
+		{
+			name:       "SRV",
+			recordType: "SRV",
+			value:      "10 5 5060 sip1.example.com.",
+			host:       "sip1.example.com.",
+			want:       true,
+		},
+		{
+			name:       "other host",
+			recordType: "NS",
+			value:      "ns2.example.com.",
+			host:       "ns1.example.com.",
+			want:       false,
+		},
+		{
+			name:       "priority is no target",
+			recordType: "MX",
+			value:      "10 mx1.example.com.",
+			host:       "10",
+			want:       false,
+		},
+		{
+			name:       "record type without target",
+			recordType: "TXT",
+			value:      "mx1.example.com.",
+			host:       "mx1.example.com.",
+			want:       false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := matchesTarget(test.recordType, test.value, test.host)
+			if got != test.want {
+				t.Fatalf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestReplaceTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		recordType string
+		value      string
+		host       string
+		want       string
+		ok         bool
+	}{
+		{
+			name:       "MX keeps priority",
+			recordType: "MX",
+			value:      "10 mx1.example.com.",
+			host:       "mx2.example.com.",
+			want:       "10 mx2.example.com.",
+			ok:         true,
+		},
+		{
+			name:       "SRV keeps priority, weight and port",
+			recordType: "SRV",
+			value:      "10 5 5060 sip1.example.com.",
+			host:       "sip2.example.com.",
+			want:       "10 5 5060 sip2.example.com.",
+			ok:         true,
+		},
+		{
+			name:       "trailing dot added",
+			recordType: "CNAME",
+			value:      "www.example.com.",
+			host:       "web.example.com",
+			want:       "web.example.com.",
+			ok:         true,
+		},
+		{
+			name:       "trailing dot removed",
+			recordType: "NS",
+			value:      "ns1",
+			host:       "ns2.",
+			want:       "ns2",
+			ok:         true,
+		},
+		{
+			name:       "missing target field",
+			recordType: "MX",
+			value:      "10",
+			host:       "mx2.example.com.",
+			ok:         false,
+		},
+		{
+			name:       "record type without target",
+			recordType: "A",
+			value:      "10.0.0.1",
+			host:       "10.0.0.2",
+			ok:         false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := replaceTarget(test.recordType, test.value, test.host)
+			if ok != test.ok {
+				t.Fatalf("expected ok=%v, got %v", test.ok, ok)
+			}
+
+			if got != test.want {
+				t.Fatalf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestFilterWithValue(t *testing.T) {
+	rec := &provider.RecordSet{
+		Name:   "example.com.",
+		Type:   "MX",
+		Values: []string{"10 mx1.example.com.", "20 mx2.example.com."},
+	}
+
+	got := filterWithValue(rec, "mx1.example.com.")
+	want := []string{"20 mx2.example.com."}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	replacement := replaceValue(rec, "10 mx1.example.com.", "mx1.example.com.", "mx3.example.com.")
+	if replacement != "10 mx3.example.com." {
+		t.Fatalf("unexpected replacement %q", replacement)
+	}
+}
@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package drain
+
+import (
+	"context"
+	"log"
+
+	"github.com/czerwonk/dns-drain/pkg/changelog"
+	"github.com/czerwonk/dns-drain/pkg/provider"
+	"github.com/czerwonk/dns-drain/pkg/result"
+)
+
+// zoneBatch collects the record set updates of a zone. Changes are written to the changelog after the batch was accepted.
+type zoneBatch struct {
+	zone    string
+	batch   *provider.Batch
+	writer  *changelog.RecordSetWriter
+	pending []pendingUpdate
+}
+
+type pendingUpdate struct {
+	rec     *provider.RecordSet
+	updated *provider.RecordSet
+}
+
+func (b *zoneBatch) add(ctx context.Context, rec *provider.RecordSet, values []string) error {
+	updated := rec.WithValues(values)
+	added, err := b.batch.UpdateRecordSet(ctx, rec, updated)
+	if added {
+		b.pending = append(b.pending, pendingUpdate{rec: rec, updated: updated})
+	}
+
+	return err
+}
+
+func (b *zoneBatch) commit(ctx context.Context, res *result.Result) {
+	if len(b.pending) == 0 {
+		return
+	}
+
+	log.Printf("%s: applying %d changes\n", b.zone, len(b.pending))
+	n, err := b.batch.Commit(ctx)
+	if err != nil {
+		log.Printf("ERROR - %s: %d of %d changes not applied: %s\n", b.zone, len(b.pending)-n, len(b.pending), err)
+	}
+
+	for i, u := range b.pending {
+		if i >= n {
+			res.AddRecordFailure(b.zone, u.rec.Name, u.rec.Type, err)
+			continue
+		}
+
+		if err := b.writer.LogChanges(b.zone, u.rec, u.updated); err != nil {
+			log.Printf("ERROR - %s: %s", u.rec.Name, err)
+			res.AddRecordFailure(b.zone, u.rec.Name, u.rec.Type, err)
+			continue
+		}
+
+		res.AddChanged()
+	}
+}
@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package gcloud
+
+import "github.com/czerwonk/dns-drain/pkg/provider"
+
+// Default quotas of a single Cloud DNS change (see https://cloud.google.com/dns/quotas)
+const (
+	maxAdditionsPerChange  = 1000
+	maxDeletionsPerChange  = 1000
+	maxRrdataSizePerChange = 100000
+)
+
+// splitChanges splits the changes into batches not exceeding the limits of a single Cloud DNS change
+func splitChanges(changes []*provider.Change) [][]*provider.Change {
+	batches := make([][]*provider.Change, 0)
+	current := make([]*provider.Change, 0)
+	additions, deletions, size := 0, 0, 0
+
+	for _, c := range changes {
+		a, d, s := changeSize(c)
+		if len(current) > 0 && (additions+a > maxAdditionsPerChange || deletions+d > maxDeletionsPerChange || size+s > maxRrdataSizePerChange) {
+			batches = append(batches, current)
+			current = make([]*provider.Change, 0)
+			additions, deletions, size = 0, 0, 0
+		}
+
+		current = append(current, c)
+		additions += a
+		deletions += d
+		size += s
+	}
+
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+
+	return batches
+}
+
+func changeSize(c *provider.Change) (additions, deletions, size int) {
+	if c.Before != nil {
+		deletions = 1
+		size += rrdataSize(c.Before)
+	}
+
+	if c.After != nil {
+		additions = 1
+		size += rrdataSize(c.After)
+	}
+
+	return additions, deletions, size
+}
+
+func rrdataSize(rec *provider.RecordSet) int {
+	size := 0
+	for _, v := range rec.Values {
+		size += len(v)
+	}
+
+	return size
+}
@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package gcloud
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+// replacements returns n changes replacing a record set with a value of the given size by one without the value
+func replacements(n, valueSize int) []*provider.Change {
+	changes := make([]*provider.Change, 0, n)
+	for i := range n {
+		rec := &provider.RecordSet{
+			Name:   fmt.Sprintf("r%d.example.com.", i),
+			Type:   "TXT",
+			TTL:    300,
+			Values: []string{strings.Repeat("x", valueSize)},
+		}
+		changes = append(changes, &provider.Change{Before: rec, After: rec.WithValues([]string{})})
+	}
+
+	return changes
+}
+
+func TestSplitChanges(t *testing.T) {
+	created := &provider.RecordSet{Name: "new.example.com.", Type: "A", TTL: 300, Values: []string{"10.0.0.1"}}
+
+	tests := []struct {
+		name    string
+		changes []*provider.Change
+		want    []int
+	}{
+		{
+			name: "no changes",
+			want: []int{},
+		},
+		{
+			name:    "within limits",
+			changes: replacements(10, 10),
+			want:    []int{10},
+		},
+		{
+			name:    "max deletions exceeded",
+			changes: replacements(maxDeletionsPerChange+1, 1),
+			want:    []int{maxDeletionsPerChange, 1},
+		},
+		{
+			name:    "max additions exceeded",
+			changes: slices.Repeat([]*provider.Change{{After: created}}, maxAdditionsPerChange*2+1),
+			want:    []int{maxAdditionsPerChange, maxAdditionsPerChange, 1},
+		},
+		{
+			name:    "max rrdata size exceeded",
+			changes: replacements(3, maxRrdataSizePerChange/2),
+			want:    []int{2, 1},
+		},
+		{
+			name:    "single change exceeding the limits",
+			changes: replacements(2, maxRrdataSizePerChange+1),
+			want:    []int{1, 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			batches := splitChanges(test.changes)
+
+			got := make([]int, 0, len(batches))
+			for _, b := range batches {
+				got = append(got, len(b))
+			}
+
+			if !slices.Equal(got, test.want) {
+				t.Fatalf("expected batches of %v changes, got %v", test.want, got)
+			}
+
+			if flat := slices.Concat(batches...); len(test.changes) > 0 && !slices.Equal(flat, test.changes) {
+				t.Fatal("expected order of the changes to be kept")
+			}
+		})
+	}
+}
@@ -11,6 +11,10 @@ import (
 type Config struct {
 	Project         string
 	CredentialsFile string
+	Retry           RetryPolicy
+
+	// RequestsPerSecond limits the rate of API requests (0 = unlimited)
+	RequestsPerSecond float64
 }
 
 func (o Config) toClientOptions() []option.ClientOption {
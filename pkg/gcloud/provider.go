@@ -0,0 +1,279 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package gcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+
+	dns "google.golang.org/api/dns/v1"
+)
+
+const providerName = "gcloud"
+
+// GoogleDnsProvider provides access to zones managed in Google Cloud DNS
+type GoogleDnsProvider struct {
+	cfg       Config
+	service   *dns.Service
+	mutex     sync.Mutex
+	submitted []*submittedChange
+}
+
+func NewProvider(ctx context.Context, cfg Config) (*GoogleDnsProvider, error) {
+	if cfg.Retry.MaxAttempts < 1 {
+		cfg.Retry = DefaultRetryPolicy
+	}
+
+	opts := cfg.toClientOptions()
+	if cfg.RequestsPerSecond > 0 {
+		var err error
+		opts, err = withRateLimit(ctx, cfg.RequestsPerSecond, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	svc, err := dns.NewService(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GoogleDnsProvider{
+		cfg:     cfg,
+		service: svc,
+	}, nil
+}
+
+func (p *GoogleDnsProvider) Name() string {
+	return providerName
+}
+
+func (p *GoogleDnsProvider) Project() string {
+	return p.cfg.Project
+}
+
+func (p *GoogleDnsProvider) ListZones(ctx context.Context) ([]string, error) {
+	var zones []string
+	err := p.cfg.Retry.do(ctx, "listing zones", func() error {
+		zones = make([]string, 0)
+		return p.service.ManagedZones.List(p.cfg.Project).Pages(ctx, func(r *dns.ManagedZonesListResponse) error {
+			for _, z := range r.ManagedZones {
+				zones = append(zones, z.Name)
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return zones, nil
+}
+
+func (p *GoogleDnsProvider) ListRecordSets(ctx context.Context, zone string) ([]*provider.RecordSet, error) {
+	var recs []*provider.RecordSet
+	err := p.cfg.Retry.do(ctx, "listing record sets of "+zone, func() error {
+		recs = make([]*provider.RecordSet, 0)
+		return p.service.ResourceRecordSets.List(p.cfg.Project, zone).Pages(ctx, func(r *dns.ResourceRecordSetsListResponse) error {
+			for _, rec := range r.Rrsets {
+				recs = append(recs, toRecordSet(rec))
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return recs, nil
+}
+
+func (p *GoogleDnsProvider) ApplyChange(ctx context.Context, zone string, change *provider.Change) error {
+	c := newChange()
+	err := addToChange(c, change)
+	if err != nil {
+		return err
+	}
+
+	return p.createChange(ctx, zone, c)
+}
+
+// ApplyChanges applies the changes in a single Cloud DNS change. Changes exceeding the API limits are split into multiple Cloud DNS changes.
+func (p *GoogleDnsProvider) ApplyChanges(ctx context.Context, zone string, changes []*provider.Change) (int, error) {
+	applied := 0
+	for _, b := range splitChanges(changes) {
+		c := newChange()
+		for _, x := range b {
+			err := addToChange(c, x)
+			if err != nil {
+				return applied, err
+			}
+		}
+
+		err := p.createChange(ctx, zone, c)
+		if err != nil {
+			return applied, err
+		}
+
+		applied += len(b)
+	}
+
+	return applied, nil
+}
+
+// createChange submits the change. Creating a change is not idempotent: a change failing with a server error
+// may have been applied anyway, so the record sets are read again before retrying.
+func (p *GoogleDnsProvider) createChange(ctx context.Context, zone string, c *dns.Change) error {
+	var res *dns.Change
+	serverError := false
+	err := p.cfg.Retry.do(ctx, "change in "+zone, func() (err error) {
+		res, err = p.service.Changes.Create(p.cfg.Project, zone, c).Context(ctx).Do()
+		if err == nil || !(isServerError(err) || (serverError && isPreconditionFailed(err))) {
+			return err
+		}
+
+		serverError = true
+		applied, checkErr := p.changeApplied(ctx, zone, c)
+		if checkErr != nil {
+			log.Printf("WARN - could not check if change in %s was applied: %s\n", zone, checkErr)
+			return &nonRetryableError{err: err}
+		}
+
+		if applied {
+			res = nil
+			return nil
+		}
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if res != nil {
+		p.addSubmitted(zone, res)
+	}
+
+	return nil
+}
+
+// changeApplied checks if the record sets already match the additions and deletions of the change
+func (p *GoogleDnsProvider) changeApplied(ctx context.Context, zone string, c *dns.Change) (bool, error) {
+	type key struct {
+		name       string
+		recordType string
+	}
+
+	expected := make(map[key]*dns.ResourceRecordSet)
+	for _, r := range c.Deletions {
+		expected[key{r.Name, r.Type}] = nil
+	}
+
+	for _, r := range c.Additions {
+		expected[key{r.Name, r.Type}] = r
+	}
+
+	for k, want := range expected {
+		var live []*dns.ResourceRecordSet
+		err := p.cfg.Retry.do(ctx, "reading "+k.name+" in "+zone, func() error {
+			res, err := p.service.ResourceRecordSets.List(p.cfg.Project, zone).Name(k.name).Type(k.recordType).Context(ctx).Do()
+			if err != nil {
+				return err
+			}
+
+			live = res.Rrsets
+			return nil
+		})
+		if err != nil {
+			return false, err
+		}
+
+		if want == nil {
+			if len(live) > 0 {
+				return false, nil
+			}
+			continue
+		}
+
+		if len(live) == 0 || live[0].Ttl != want.Ttl || !provider.SameValues(live[0].Rrdatas, want.Rrdatas) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func newChange() *dns.Change {
+	return &dns.Change{
+		Additions: make([]*dns.ResourceRecordSet, 0),
+		Deletions: make([]*dns.ResourceRecordSet, 0),
+	}
+}
+
+func addToChange(c *dns.Change, change *provider.Change) error {
+	if change.Before != nil {
+		rec, err := fromRecordSet(change.Before)
+		if err != nil {
+			return err
+		}
+		c.Deletions = append(c.Deletions, rec)
+	}
+
+	if change.After != nil {
+		rec, err := fromRecordSet(change.After)
+		if err != nil {
+			return err
+		}
+		c.Additions = append(c.Additions, rec)
+	}
+
+	return nil
+}
+
+func toRecordSet(rec *dns.ResourceRecordSet) *provider.RecordSet {
+	r := &provider.RecordSet{
+		Name:   rec.Name,
+		Type:   rec.Type,
+		TTL:    rec.Ttl,
+		Values: rec.Rrdatas,
+		Raw:    rec,
+	}
+
+	if rec.RoutingPolicy != nil {
+		b, err := json.Marshal(rec.RoutingPolicy)
+		if err == nil {
+			r.RoutingPolicy = b
+		}
+	}
+
+	return r
+}
+
+func fromRecordSet(rec *provider.RecordSet) (*dns.ResourceRecordSet, error) {
+	res := &dns.ResourceRecordSet{}
+	if raw, ok := rec.Raw.(*dns.ResourceRecordSet); ok {
+		*res = *raw
+	} else if len(rec.RoutingPolicy) > 0 {
+		res.RoutingPolicy = &dns.RRSetRoutingPolicy{}
+		err := json.Unmarshal(rec.RoutingPolicy, res.RoutingPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid routing policy for %s %s: %w", rec.Name, rec.Type, err)
+		}
+	}
+
+	res.Name = rec.Name
+	res.Type = rec.Type
+	res.Ttl = rec.TTL
+	res.Rrdatas = rec.Values
+
+	return res, nil
+}
@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package gcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/option"
+
+	dns "google.golang.org/api/dns/v1"
+)
+
+const testProject = "test-project"
+
+// standIn answers the Cloud DNS API calls used by the provider
+type standIn struct {
+	mutex  sync.Mutex
+	rrsets map[string][]*dns.ResourceRecordSet
+
+	// changes submitted per zone, the id of a change is its index
+	changes map[string][]*dns.Change
+
+	// pendingPolls is the number of polls a change is reported as pending
+	pendingPolls int
+	polls        map[string]int
+
+	// failures are returned by the next create calls. A failure with applied set is returned after the change was applied.
+	failures []failure
+}
+
+type failure struct {
+	code    int
+	applied bool
+}
+
+func (s *standIn) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	prefix := "/dns/v1/projects/" + testProject + "/managedZones/{zone}"
+	mux.HandleFunc("GET "+prefix+"/rrsets", s.listRecordSets)
+	mux.HandleFunc("POST "+prefix+"/changes", s.createChange)
+	mux.HandleFunc("GET "+prefix+"/changes/{id}", s.getChange)
+
+	return mux
+}
+
+func (s *standIn) listRecordSets(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	name, recordType := r.URL.Query().Get("name"), r.URL.Query().Get("type")
+	rrsets := make([]*dns.ResourceRecordSet, 0)
+	for _, rec := range s.rrsets[r.PathValue("zone")] {
+		if (name == "" || rec.Name == name) && (recordType == "" || rec.Type == recordType) {
+			rrsets = append(rrsets, rec)
+		}
+	}
+
+	writeJSON(w, &dns.ResourceRecordSetsListResponse{Rrsets: rrsets})
+}
+
+func (s *standIn) createChange(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	c := &dns.Change{}
+	if err := json.NewDecoder(r.Body).Decode(c); err != nil {
+		writeError(w, http.StatusBadRequest)
+		return
+	}
+
+	var f *failure
+	if len(s.failures) > 0 {
+		f = &s.failures[0]
+		s.failures = s.failures[1:]
+	}
+
+	if f != nil && !f.applied {
+		writeError(w, f.code)
+		return
+	}
+
+	zone := r.PathValue("zone")
+	s.apply(zone, c)
+	c.Id = strconv.Itoa(len(s.changes[zone]))
+	c.Status = "pending"
+	s.changes[zone] = append(s.changes[zone], c)
+
+	if f != nil {
+		writeError(w, f.code)
+		return
+	}
+
+	writeJSON(w, c)
+}
+
+func (s *standIn) apply(zone string, c *dns.Change) {
+	for _, d := range c.Deletions {
+		for i, rec := range s.rrsets[zone] {
+			if rec.Name == d.Name && rec.Type == d.Type {
+				s.rrsets[zone] = append(s.rrsets[zone][:i], s.rrsets[zone][i+1:]...)
+				break
+			}
+		}
+	}
+
+	s.rrsets[zone] = append(s.rrsets[zone], c.Additions...)
+}
+
+func (s *standIn) getChange(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	zone, id := r.PathValue("zone"), r.PathValue("id")
+	idx, err := strconv.Atoi(id)
+	if err != nil || idx >= len(s.changes[zone]) {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+
+	c := *s.changes[zone][idx]
+	s.polls[zone+"/"+id]++
+	if s.pendingPolls >= 0 && s.polls[zone+"/"+id] > s.pendingPolls {
+		c.Status = changeStatusDone
+	}
+
+	writeJSON(w, &c)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	fmt.Fprintf(w, `{"error":{"code":%d,"message":"%s"}}`, code, http.StatusText(code))
+}
+
+func newTestProvider(t *testing.T, s *standIn) *GoogleDnsProvider {
+	t.Helper()
+
+	if s.rrsets == nil {
+		s.rrsets = make(map[string][]*dns.ResourceRecordSet)
+	}
+	s.changes = make(map[string][]*dns.Change)
+	s.polls = make(map[string]int)
+
+	srv := httptest.NewServer(s.routes())
+	t.Cleanup(srv.Close)
+
+	svc, err := dns.NewService(context.Background(), option.WithEndpoint(srv.URL+"/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &GoogleDnsProvider{
+		cfg:     Config{Project: testProject, Retry: RetryPolicy{MaxAttempts: 3}},
+		service: svc,
+	}
+}
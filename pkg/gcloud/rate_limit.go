@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package gcloud
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+)
+
+// rateLimitedTransport delays requests exceeding the configured rate
+type rateLimitedTransport struct {
+	base    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	err := t.limiter.Wait(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// withRateLimit returns client options limiting the requests sent by the client to rps per second
+func withRateLimit(ctx context.Context, rps float64, opts []option.ClientOption) ([]option.ClientOption, error) {
+	base := &rateLimitedTransport{
+		base:    http.DefaultTransport,
+		limiter: rate.NewLimiter(rate.Limit(rps), 1),
+	}
+
+	t, err := htransport.NewTransport(ctx, base, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(opts, option.WithHTTPClient(&http.Client{Transport: t})), nil
+}
@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package gcloud
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// RetryPolicy defines how API calls failing due to rate limits or transient errors are retried
+type RetryPolicy struct {
+	// MaxAttempts is the max number of attempts per call (1 = no retries)
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. It is doubled for every further retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff limits the delay between two attempts
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is used if no retry policy is configured
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+}
+
+// do calls f until it succeeds, fails with an error not worth retrying or the max number of attempts is reached
+func (r RetryPolicy) do(ctx context.Context, op string, f func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil || attempt >= r.MaxAttempts || !isRetryable(err) {
+			return err
+		}
+
+		delay := r.delay(attempt, err)
+		log.Printf("WARN - %s failed (attempt %d of %d), retrying in %s: %s\n", op, attempt, r.MaxAttempts, delay, err)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// delay returns the duration to wait before the next attempt. A delay requested by the API (Retry-After) takes precedence.
+func (r RetryPolicy) delay(attempt int, err error) time.Duration {
+	if d, ok := retryAfter(err); ok {
+		return d
+	}
+
+	backoff := r.InitialBackoff << (attempt - 1)
+	if backoff <= 0 || (r.MaxBackoff > 0 && backoff > r.MaxBackoff) {
+		backoff = r.MaxBackoff
+	}
+
+	if backoff <= 0 {
+		return 0
+	}
+
+	// equal jitter: half of the backoff is fixed, the other half random
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+// nonRetryableError wraps an error which must not be retried even if the API reported a transient error
+type nonRetryableError struct {
+	err error
+}
+
+func (e *nonRetryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *nonRetryableError) Unwrap() error {
+	return e.err
+}
+
+func isRetryable(err error) bool {
+	var nonRetryable *nonRetryableError
+	if errors.As(err, &nonRetryable) {
+		return false
+	}
+
+	return apiErrorCode(err) == http.StatusTooManyRequests || isServerError(err)
+}
+
+func isServerError(err error) bool {
+	return apiErrorCode(err) >= http.StatusInternalServerError
+}
+
+func isPreconditionFailed(err error) bool {
+	code := apiErrorCode(err)
+	return code == http.StatusPreconditionFailed || code == http.StatusConflict
+}
+
+func apiErrorCode(err error) int {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return 0
+	}
+
+	return apiErr.Code
+}
+
+func retryAfter(err error) (time.Duration, bool) {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Header == nil {
+		return 0, false
+	}
+
+	v := apiErr.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,200 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package gcloud
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+
+	dns "google.golang.org/api/dns/v1"
+)
+
+func TestRetryPolicyDo(t *testing.T) {
+	tooManyRequests := &googleapi.Error{Code: http.StatusTooManyRequests}
+	unavailable := &googleapi.Error{Code: http.StatusServiceUnavailable}
+	badRequest := &googleapi.Error{Code: http.StatusBadRequest}
+
+	tests := []struct {
+		name    string
+		errs    []error
+		calls   int
+		wantErr error
+	}{
+		{
+			name:  "success",
+			errs:  []error{nil},
+			calls: 1,
+		},
+		{
+			name:  "rate limited",
+			errs:  []error{tooManyRequests, nil},
+			calls: 2,
+		},
+		{
+			name:  "server error",
+			errs:  []error{unavailable, unavailable, nil},
+			calls: 3,
+		},
+		{
+			name:    "max attempts reached",
+			errs:    []error{unavailable, unavailable, unavailable, nil},
+			calls:   3,
+			wantErr: unavailable,
+		},
+		{
+			name:    "client error",
+			errs:    []error{badRequest, nil},
+			calls:   1,
+			wantErr: badRequest,
+		},
+		{
+			name:    "server error not retryable",
+			errs:    []error{&nonRetryableError{err: unavailable}, nil},
+			calls:   1,
+			wantErr: unavailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			err := RetryPolicy{MaxAttempts: 3}.do(context.Background(), "test", func() error {
+				calls++
+				return test.errs[calls-1]
+			})
+
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("expected %v, got %v", test.wantErr, err)
+			}
+
+			if calls != test.calls {
+				t.Fatalf("expected %d calls, got %d", test.calls, calls)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	r := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 4 * time.Second}
+
+	tests := []struct {
+		name     string
+		attempt  int
+		err      error
+		min, max time.Duration
+	}{
+		{
+			name:    "first retry",
+			attempt: 1,
+			err:     &googleapi.Error{Code: http.StatusServiceUnavailable},
+			min:     500 * time.Millisecond,
+			max:     time.Second,
+		},
+		{
+			name:    "backoff doubled",
+			attempt: 2,
+			err:     &googleapi.Error{Code: http.StatusServiceUnavailable},
+			min:     time.Second,
+			max:     2 * time.Second,
+		},
+		{
+			name:    "max backoff",
+			attempt: 10,
+			err:     &googleapi.Error{Code: http.StatusServiceUnavailable},
+			min:     2 * time.Second,
+			max:     4 * time.Second,
+		},
+		{
+			name:    "retry after",
+			attempt: 1,
+			err:     &googleapi.Error{Code: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"7"}}},
+			min:     7 * time.Second,
+			max:     7 * time.Second,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := r.delay(test.attempt, test.err)
+			if d < test.min || d > test.max {
+				t.Fatalf("expected delay between %s and %s, got %s", test.min, test.max, d)
+			}
+		})
+	}
+}
+
+func TestCreateChangeRetry(t *testing.T) {
+	www := &provider.RecordSet{Name: "www.example.com.", Type: "A", TTL: 300, Values: []string{"10.0.0.1", "10.0.0.2"}}
+
+	tests := []struct {
+		name     string
+		failures []failure
+		wantErr  bool
+	}{
+		{
+			name:     "server error before the change was applied",
+			failures: []failure{{code: http.StatusInternalServerError}},
+		},
+		{
+			name:     "server error after the change was applied",
+			failures: []failure{{code: http.StatusBadGateway, applied: true}},
+		},
+		{
+			name:     "precondition failed after server error",
+			failures: []failure{{code: http.StatusInternalServerError}, {code: http.StatusPreconditionFailed}},
+			wantErr:  true,
+		},
+		{
+			name:     "client error",
+			failures: []failure{{code: http.StatusBadRequest}},
+			wantErr:  true,
+		},
+		{
+			name: "max attempts reached",
+			failures: []failure{
+				{code: http.StatusServiceUnavailable},
+				{code: http.StatusServiceUnavailable},
+				{code: http.StatusServiceUnavailable},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &standIn{
+				rrsets:   map[string][]*dns.ResourceRecordSet{"example-com": {{Name: www.Name, Type: www.Type, Ttl: www.TTL, Rrdatas: www.Values}}},
+				failures: test.failures,
+			}
+			p := newTestProvider(t, s)
+
+			err := p.ApplyChange(context.Background(), "example-com", &provider.Change{Before: www, After: www.WithValues([]string{"10.0.0.2"})})
+			if test.wantErr != (err != nil) {
+				t.Fatalf("expected error=%v, got %v", test.wantErr, err)
+			}
+
+			if test.wantErr {
+				return
+			}
+
+			if n := len(s.changes["example-com"]); n != 1 {
+				t.Fatalf("expected change to be applied once, got %d", n)
+			}
+
+			live := s.rrsets["example-com"]
+			if len(live) != 1 || !slices.Equal(live[0].Rrdatas, []string{"10.0.0.2"}) {
+				t.Fatalf("unexpected record sets %+v", live)
+			}
+		})
+	}
+}
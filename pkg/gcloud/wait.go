@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package gcloud
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+
+	dns "google.golang.org/api/dns/v1"
+)
+
+const (
+	changeStatusDone = "done"
+	pollInterval     = 2 * time.Second
+)
+
+// submittedChange is a Cloud DNS change which was accepted but possibly not completed yet
+type submittedChange struct {
+	zone   string
+	change *dns.Change
+	err    error
+}
+
+func (p *GoogleDnsProvider) addSubmitted(zone string, c *dns.Change) {
+	if c == nil || c.Status == changeStatusDone {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.submitted = append(p.submitted, &submittedChange{zone: zone, change: c})
+}
+
+// WaitForChanges polls all changes submitted so far until their status is done or ctx is done
+func (p *GoogleDnsProvider) WaitForChanges(ctx context.Context) []*provider.IncompleteChange {
+	p.mutex.Lock()
+	pending := p.submitted
+	p.submitted = nil
+	p.mutex.Unlock()
+
+	for len(pending) > 0 {
+		log.Printf("%d changes pending\n", len(pending))
+		pending = p.pollChanges(ctx, pending)
+		if len(pending) == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return incompleteChanges(pending, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+
+	return nil
+}
+
+func (p *GoogleDnsProvider) pollChanges(ctx context.Context, changes []*submittedChange) []*submittedChange {
+	pending := make([]*submittedChange, 0)
+	for _, c := range changes {
+		res, err := p.service.Changes.Get(p.cfg.Project, c.zone, c.change.Id).Context(ctx).Do()
+		if err != nil {
+			c.err = err
+			pending = append(pending, c)
+			continue
+		}
+
+		if res.Status != changeStatusDone {
+			pending = append(pending, c)
+		}
+	}
+
+	return pending
+}
+
+func incompleteChanges(changes []*submittedChange, err error) []*provider.IncompleteChange {
+	res := make([]*provider.IncompleteChange, 0)
+	for _, c := range changes {
+		e := fmt.Errorf("change %s not completed: %w", c.change.Id, err)
+		if c.err != nil {
+			e = fmt.Errorf("%w (last error: %s)", e, c.err)
+		}
+
+		for _, rec := range affectedRecordSets(c.change) {
+			res = append(res, &provider.IncompleteChange{
+				Zone:       c.zone,
+				Record:     rec.Name,
+				RecordType: rec.Type,
+				Err:        e,
+			})
+		}
+	}
+
+	return res
+}
+
+// affectedRecordSets returns the record sets changed by c. A replaced record set is returned once.
+func affectedRecordSets(c *dns.Change) []*dns.ResourceRecordSet {
+	type key struct {
+		name       string
+		recordType string
+	}
+
+	seen := make(map[key]bool)
+	recs := make([]*dns.ResourceRecordSet, 0)
+	for _, rec := range append(c.Deletions, c.Additions...) {
+		k := key{name: rec.Name, recordType: rec.Type}
+		if !seen[k] {
+			seen[k] = true
+			recs = append(recs, rec)
+		}
+	}
+
+	return recs
+}
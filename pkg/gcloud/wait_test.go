@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package gcloud
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+func TestWaitForChanges(t *testing.T) {
+	www := &provider.RecordSet{Name: "www.example.com.", Type: "A", TTL: 300, Values: []string{"10.0.0.1", "10.0.0.2"}}
+	api := &provider.RecordSet{Name: "api.example.com.", Type: "A", TTL: 300, Values: []string{"10.0.0.1", "10.0.0.3"}}
+
+	tests := []struct {
+		name         string
+		pendingPolls int
+		timeout      time.Duration
+		incomplete   int
+	}{
+		{
+			name:         "completed",
+			pendingPolls: 0,
+			timeout:      time.Second,
+		},
+		{
+			name:         "not completed before timeout",
+			pendingPolls: -1,
+			timeout:      100 * time.Millisecond,
+			incomplete:   2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := newTestProvider(t, &standIn{pendingPolls: test.pendingPolls})
+
+			_, err := p.ApplyChanges(context.Background(), "example-com", []*provider.Change{
+				{Before: www, After: www.WithValues([]string{"10.0.0.2"})},
+				{Before: api, After: api.WithValues([]string{"10.0.0.3"})},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), test.timeout)
+			defer cancel()
+
+			incomplete := p.WaitForChanges(ctx)
+			if len(incomplete) != test.incomplete {
+				t.Fatalf("expected %d incomplete changes, got %d", test.incomplete, len(incomplete))
+			}
+
+			for _, c := range incomplete {
+				if c.Zone != "example-com" || !errors.Is(c.Err, context.DeadlineExceeded) {
+					t.Fatalf("unexpected incomplete change %+v", c)
+				}
+			}
+
+			if again := p.WaitForChanges(context.Background()); len(again) != 0 {
+				t.Fatalf("expected changes to be waited for once, got %d", len(again))
+			}
+		})
+	}
+}
+
+func TestAffectedRecordSets(t *testing.T) {
+	c := newChange()
+	rec := &provider.RecordSet{Name: "www.example.com.", Type: "A", TTL: 300, Values: []string{"10.0.0.1", "10.0.0.2"}}
+	created := &provider.RecordSet{Name: "api.example.com.", Type: "A", TTL: 300, Values: []string{"10.0.0.3"}}
+
+	for _, change := range []*provider.Change{{Before: rec, After: rec.WithValues([]string{"10.0.0.2"})}, {After: created}} {
+		if err := addToChange(c, change); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recs := affectedRecordSets(c)
+	if len(recs) != 2 || recs[0].Name != "www.example.com." || recs[1].Name != "api.example.com." {
+		t.Fatalf("expected replaced record set to be returned once, got %d record sets", len(recs))
+	}
+}
@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package plan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+var ErrPlanExists = errors.New("plan file already exists")
+
+// Plan contains record set changes computed by a drain, which can be applied later
+type Plan struct {
+	Provider  string    `json:"provider"`
+	Project   string    `json:"project,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	Changes   []*Change `json:"changes"`
+}
+
+// Change is the replacement of a record set. Before is the state the change was computed from.
+type Change struct {
+	Zone          string          `json:"zone"`
+	Record        string          `json:"record"`
+	RecordType    string          `json:"recordType"`
+	TTL           int64           `json:"ttl"`
+	RoutingPolicy json.RawMessage `json:"routingPolicy,omitempty"`
+	Before        []string        `json:"before"`
+	After         []string        `json:"after"`
+}
+
+func Load(path string) (*Plan, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Plan{}
+	err = json.Unmarshal(b, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Save writes the plan to a new file. An existing file is only replaced if overwrite is set.
+func (p *Plan) Save(path string, overwrite bool) error {
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	f, err := os.OpenFile(path, flags, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s: %w", path, ErrPlanExists)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(b)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// CheckNew returns ErrPlanExists if the plan file exists and overwrite is not set
+func CheckNew(path string, overwrite bool) error {
+	if overwrite {
+		return nil
+	}
+
+	_, err := os.Stat(path)
+	if err == nil {
+		return fmt.Errorf("%s: %w", path, ErrPlanExists)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// GroupByZone returns the changes of the plan per zone
+func (p *Plan) GroupByZone() map[string][]*Change {
+	m := make(map[string][]*Change)
+	for _, c := range p.Changes {
+		m[c.Zone] = append(m[c.Zone], c)
+	}
+
+	return m
+}
+
+// Matches checks if the live record set (nil if not existing) is still in the state the change was computed from
+func (c *Change) Matches(rec *provider.RecordSet) bool {
+	if rec == nil || len(rec.Values) == 0 {
+		return len(c.Before) == 0
+	}
+
+	if rec.TTL != c.TTL {
+		return false
+	}
+
+	return provider.SameValues(rec.Values, c.Before)
+}
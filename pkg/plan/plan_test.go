@@ -0,0 +1,163 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package plan
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+// failingProvider fails the test if a change is applied
+type failingProvider struct {
+	t *testing.T
+}
+
+func (p failingProvider) Name() string {
+	return "test"
+}
+
+func (p failingProvider) ListZones(context.Context) ([]string, error) {
+	return []string{"example.com."}, nil
+}
+
+func (p failingProvider) ListRecordSets(context.Context, string) ([]*provider.RecordSet, error) {
+	return nil, nil
+}
+
+func (p failingProvider) ApplyChange(context.Context, string, *provider.Change) error {
+	p.t.Fatal("change applied to provider")
+	return nil
+}
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder(failingProvider{t: t})
+
+	www := &provider.RecordSet{Name: "www.example.com.", Type: "A", TTL: 300, Values: []string{"10.0.0.1", "10.0.0.2"}}
+	err := r.ApplyChange(context.Background(), "example.com.", &provider.Change{Before: www, After: www.WithValues([]string{"10.0.0.2"})})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := &provider.RecordSet{Name: "api.example.com.", Type: "A", TTL: 60, Values: []string{"10.0.0.3"}}
+	err = r.ApplyChange(context.Background(), "example.com.", &provider.Change{After: api})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pl := r.Plan()
+	if pl.Provider != "test" || len(pl.Changes) != 2 {
+		t.Fatalf("unexpected plan %+v", pl)
+	}
+
+	c := pl.Changes[0]
+	if c.Zone != "example.com." || c.Record != "www.example.com." || c.RecordType != "A" || c.TTL != 300 ||
+		!slices.Equal(c.Before, []string{"10.0.0.1", "10.0.0.2"}) || !slices.Equal(c.After, []string{"10.0.0.2"}) {
+		t.Fatalf("unexpected change %+v", c)
+	}
+
+	created := pl.Changes[1]
+	if created.TTL != 60 || len(created.Before) != 0 || !slices.Equal(created.After, []string{"10.0.0.3"}) {
+		t.Fatalf("unexpected change for new record set %+v", created)
+	}
+}
+
+func TestSave(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  bool
+		overwrite bool
+		wantErr   error
+	}{
+		{
+			name: "new file",
+		},
+		{
+			name:     "existing file refused",
+			existing: true,
+			wantErr:  ErrPlanExists,
+		},
+		{
+			name:      "existing file overwritten",
+			existing:  true,
+			overwrite: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "plan.json")
+			if test.existing {
+				err := os.WriteFile(path, []byte("previous plan with more content than the new one"), 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := CheckNew(path, test.overwrite); !errors.Is(err, test.wantErr) {
+				t.Fatalf("expected check to return %v, got %v", test.wantErr, err)
+			}
+
+			pl := &Plan{Provider: "test", Changes: []*Change{{Zone: "example.com.", Record: "www.example.com.", RecordType: "A"}}}
+			err := pl.Save(path, test.overwrite)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("expected %v, got %v", test.wantErr, err)
+			}
+
+			if test.wantErr != nil {
+				return
+			}
+
+			loaded, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(loaded.Changes) != 1 || loaded.Changes[0].Record != "www.example.com." {
+				t.Fatalf("unexpected plan loaded %+v", loaded)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	c := &Change{TTL: 300, Before: []string{"10.0.0.1", "10.0.0.2"}}
+
+	tests := []struct {
+		name string
+		rec  *provider.RecordSet
+		want bool
+	}{
+		{
+			name: "unchanged",
+			rec:  &provider.RecordSet{TTL: 300, Values: []string{"10.0.0.2", "10.0.0.1"}},
+			want: true,
+		},
+		{
+			name: "value added",
+			rec:  &provider.RecordSet{TTL: 300, Values: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+		},
+		{
+			name: "TTL changed",
+			rec:  &provider.RecordSet{TTL: 60, Values: []string{"10.0.0.1", "10.0.0.2"}},
+		},
+		{
+			name: "record set removed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := c.Matches(test.rec); got != test.want {
+				t.Fatalf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
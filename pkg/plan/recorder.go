@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package plan
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+// Recorder wraps a provider and adds changes to a plan instead of applying them
+type Recorder struct {
+	provider.Provider
+	mutex sync.Mutex
+	plan  *Plan
+}
+
+func NewRecorder(p provider.Provider) *Recorder {
+	r := &Recorder{
+		Provider: p,
+		plan: &Plan{
+			Provider:  p.Name(),
+			CreatedAt: time.Now().UTC(),
+			Changes:   make([]*Change, 0),
+		},
+	}
+
+	if pp, ok := p.(provider.ProjectProvider); ok {
+		r.plan.Project = pp.Project()
+	}
+
+	return r
+}
+
+func (r *Recorder) Project() string {
+	return r.plan.Project
+}
+
+func (r *Recorder) ApplyChange(ctx context.Context, zone string, change *provider.Change) error {
+	rec := change.Before
+	if rec == nil {
+		rec = change.After
+	}
+
+	c := &Change{
+		Zone:          zone,
+		Record:        rec.Name,
+		RecordType:    rec.Type,
+		TTL:           rec.TTL,
+		RoutingPolicy: rec.RoutingPolicy,
+		Before:        make([]string, 0),
+		After:         make([]string, 0),
+	}
+
+	if change.Before != nil {
+		c.Before = change.Before.Values
+	}
+
+	if change.After != nil {
+		c.After = change.After.Values
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.plan.Changes = append(r.plan.Changes, c)
+
+	return nil
+}
+
+func (r *Recorder) Plan() *Plan {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.plan
+}
@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package powerdns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	changeTypeReplace = "REPLACE"
+	changeTypeDelete  = "DELETE"
+)
+
+type apiClient struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+type zone struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	RRsets []rrset `json:"rrsets,omitempty"`
+}
+
+type rrset struct {
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	TTL        int64     `json:"ttl,omitempty"`
+	ChangeType string    `json:"changetype,omitempty"`
+	Records    []record  `json:"records"`
+	Comments   []comment `json:"comments"`
+}
+
+type record struct {
+	Content  string `json:"content"`
+	Disabled bool   `json:"disabled"`
+}
+
+type comment struct {
+	Content    string `json:"content"`
+	Account    string `json:"account"`
+	ModifiedAt int64  `json:"modified_at,omitempty"`
+}
+
+type patchRequest struct {
+	RRsets []rrset `json:"rrsets"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+func newApiClient(cfg Config) *apiClient {
+	serverID := cfg.ServerID
+	if serverID == "" {
+		serverID = "localhost"
+	}
+
+	return &apiClient{
+		baseURL:    strings.TrimSuffix(cfg.URL, "/") + "/api/v1/servers/" + url.PathEscape(serverID),
+		apiKey:     cfg.ApiKey,
+		httpClient: http.DefaultClient,
+	}
+}
+
+func (c *apiClient) listZones(ctx context.Context) ([]zone, error) {
+	zones := make([]zone, 0)
+	err := c.do(ctx, http.MethodGet, "/zones", nil, &zones)
+	return zones, err
+}
+
+func (c *apiClient) getZone(ctx context.Context, zoneID string) (*zone, error) {
+	z := &zone{}
+	err := c.do(ctx, http.MethodGet, "/zones/"+url.PathEscape(zoneID), nil, z)
+	return z, err
+}
+
+func (c *apiClient) patchZone(ctx context.Context, zoneID string, rrsets []rrset) error {
+	return c.do(ctx, http.MethodPatch, "/zones/"+url.PathEscape(zoneID), &patchRequest{RRsets: rrsets}, nil)
+}
+
+func (c *apiClient) do(ctx context.Context, method, path string, body any, result any) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-API-Key", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		e := &apiError{}
+		if json.NewDecoder(resp.Body).Decode(e) == nil && e.Error != "" {
+			return fmt.Errorf("%s %s: %s (HTTP %d)", method, path, e.Error, resp.StatusCode)
+		}
+
+		return fmt.Errorf("%s %s: unexpected status %s", method, path, resp.Status)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
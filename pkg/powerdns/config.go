@@ -0,0 +1,11 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package powerdns
+
+type Config struct {
+	URL      string
+	ApiKey   string
+	ServerID string
+}
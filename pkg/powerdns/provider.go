@@ -0,0 +1,204 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package powerdns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+const (
+	providerName      = "powerdns"
+	commentsAttribute = "comments"
+	defaultTTL        = 3600
+)
+
+// PowerDnsProvider provides access to zones using the PowerDNS Authoritative HTTP API.
+// Disabled flags and comments of RRsets are preserved on changes and recorded in the changelog.
+type PowerDnsProvider struct {
+	client *apiClient
+	zones  *provider.ZoneIDs
+}
+
+func NewProvider(cfg Config) *PowerDnsProvider {
+	return &PowerDnsProvider{
+		client: newApiClient(cfg),
+		zones:  provider.NewZoneIDs(),
+	}
+}
+
+func (p *PowerDnsProvider) Name() string {
+	return providerName
+}
+
+func (p *PowerDnsProvider) ListZones(ctx context.Context) ([]string, error) {
+	zones, err := p.client.listZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(zones))
+	for _, z := range zones {
+		p.zones.Add(z.Name, z.ID)
+		names = append(names, z.Name)
+	}
+
+	return names, nil
+}
+
+func (p *PowerDnsProvider) ListRecordSets(ctx context.Context, zone string) ([]*provider.RecordSet, error) {
+	id, err := p.zones.Get(ctx, zone, p)
+	if err != nil {
+		return nil, err
+	}
+
+	z, err := p.client.getZone(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	recs := make([]*provider.RecordSet, 0, len(z.RRsets))
+	for _, r := range z.RRsets {
+		if r.Type == "SOA" || len(r.Records) == 0 {
+			continue
+		}
+
+		recs = append(recs, toRecordSet(r))
+	}
+
+	return recs, nil
+}
+
+func (p *PowerDnsProvider) ApplyChange(ctx context.Context, zone string, change *provider.Change) error {
+	id, err := p.zones.Get(ctx, zone, p)
+	if err != nil {
+		return err
+	}
+
+	if change.After == nil {
+		return p.client.patchZone(ctx, id, []rrset{{
+			Name:       change.Before.Name,
+			Type:       change.Before.Type,
+			ChangeType: changeTypeDelete,
+			Records:    []record{},
+			Comments:   []comment{},
+		}})
+	}
+
+	r, err := fromRecordSet(change.After, change.Before)
+	if err != nil {
+		return err
+	}
+
+	return p.client.patchZone(ctx, id, []rrset{r})
+}
+
+func toRecordSet(r rrset) *provider.RecordSet {
+	rec := &provider.RecordSet{
+		Name:       r.Name,
+		Type:       r.Type,
+		TTL:        r.TTL,
+		Values:     make([]string, 0, len(r.Records)),
+		Attributes: make(map[string]map[string]string),
+		Raw:        r,
+	}
+
+	var comments string
+	if len(r.Comments) > 0 {
+		b, err := json.Marshal(r.Comments)
+		if err == nil {
+			comments = string(b)
+		}
+	}
+
+	for _, x := range r.Records {
+		rec.Values = append(rec.Values, x.Content)
+
+		attr := map[string]string{provider.DisabledAttribute: strconv.FormatBool(x.Disabled)}
+		if len(comments) > 0 {
+			attr[commentsAttribute] = comments
+		}
+		rec.Attributes[x.Content] = attr
+	}
+
+	return rec
+}
+
+func fromRecordSet(rec *provider.RecordSet, before *provider.RecordSet) (rrset, error) {
+	r := rrset{
+		Name:       rec.Name,
+		Type:       rec.Type,
+		TTL:        rec.TTL,
+		ChangeType: changeTypeReplace,
+		Records:    make([]record, 0, len(rec.Values)),
+		Comments:   make([]comment, 0),
+	}
+
+	if r.TTL <= 0 {
+		r.TTL = defaultTTL
+	}
+
+	for _, v := range rec.Values {
+		disabled, err := disabledFlag(rec, v)
+		if err != nil {
+			return r, err
+		}
+
+		r.Records = append(r.Records, record{Content: v, Disabled: disabled})
+	}
+
+	comments, err := recordComments(rec, before)
+	if err != nil {
+		return r, err
+	}
+	r.Comments = comments
+
+	return r, nil
+}
+
+func disabledFlag(rec *provider.RecordSet, value string) (bool, error) {
+	s, found := rec.Attributes[value][provider.DisabledAttribute]
+	if !found {
+		return false, nil
+	}
+
+	disabled, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("invalid disabled flag for %s %s %s: %w", rec.Name, rec.Type, value, err)
+	}
+
+	return disabled, nil
+}
+
+// recordComments returns the comments of the existing RRset. If the RRset does not exist anymore
+// the comments recorded in the changelog are restored.
+func recordComments(rec *provider.RecordSet, before *provider.RecordSet) ([]comment, error) {
+	if before != nil {
+		if raw, ok := before.Raw.(rrset); ok && raw.Comments != nil {
+			return raw.Comments, nil
+		}
+	}
+
+	for _, v := range rec.Values {
+		s, found := rec.Attributes[v][commentsAttribute]
+		if !found {
+			continue
+		}
+
+		comments := make([]comment, 0)
+		err := json.Unmarshal([]byte(s), &comments)
+		if err != nil {
+			return nil, fmt.Errorf("invalid comments for %s %s: %w", rec.Name, rec.Type, err)
+		}
+
+		return comments, nil
+	}
+
+	return []comment{}, nil
+}
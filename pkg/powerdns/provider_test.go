@@ -0,0 +1,224 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package powerdns
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+const testApiKey = "secret"
+
+// standIn answers the PowerDNS API calls used by the provider
+type standIn struct {
+	mutex   sync.Mutex
+	zones   map[string]*zone
+	patches map[string][]rrset
+}
+
+func (s *standIn) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/servers/localhost/zones", s.listZones)
+	mux.HandleFunc("GET /api/v1/servers/localhost/zones/{id}", s.getZone)
+	mux.HandleFunc("PATCH /api/v1/servers/localhost/zones/{id}", s.patchZone)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != testApiKey {
+			writeJSON(w, http.StatusUnauthorized, &apiError{Error: "Unauthorized"})
+			return
+		}
+
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (s *standIn) listZones(w http.ResponseWriter, r *http.Request) {
+	zones := make([]zone, 0, len(s.zones))
+	for _, z := range s.zones {
+		zones = append(zones, zone{ID: z.ID, Name: z.Name})
+	}
+
+	slices.SortFunc(zones, func(a, b zone) int { return strings.Compare(a.Name, b.Name) })
+	writeJSON(w, http.StatusOK, zones)
+}
+
+func (s *standIn) getZone(w http.ResponseWriter, r *http.Request) {
+	z, found := s.zones[r.PathValue("id")]
+	if !found {
+		writeJSON(w, http.StatusNotFound, &apiError{Error: "Could not find domain"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, z)
+}
+
+func (s *standIn) patchZone(w http.ResponseWriter, r *http.Request) {
+	req := &patchRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeJSON(w, http.StatusBadRequest, &apiError{Error: err.Error()})
+		return
+	}
+
+	id := r.PathValue("id")
+	s.patches[id] = append(s.patches[id], req.RRsets...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func newTestProvider(t *testing.T) (*PowerDnsProvider, *standIn) {
+	t.Helper()
+
+	s := &standIn{
+		zones: map[string]*zone{
+			"example.com.": {
+				ID:   "example.com.",
+				Name: "example.com.",
+				RRsets: []rrset{
+					{Name: "example.com.", Type: "SOA", TTL: 3600, Records: []record{{Content: "ns1.example.com. hostmaster.example.com. 1 3600 900 604800 300"}}},
+					{Name: "www.example.com.", Type: "A", TTL: 300,
+						Records:  []record{{Content: "10.0.0.1"}, {Content: "10.0.0.2", Disabled: true}},
+						Comments: []comment{{Content: "web servers", Account: "ops"}}},
+					{Name: "api.example.com.", Type: "A", TTL: 300, Records: []record{{Content: "10.0.0.3"}}},
+				},
+			},
+			"example.org.": {ID: "example.org.", Name: "example.org."},
+		},
+		patches: make(map[string][]rrset),
+	}
+
+	srv := httptest.NewServer(s.routes())
+	t.Cleanup(srv.Close)
+
+	return NewProvider(Config{URL: srv.URL, ApiKey: testApiKey}), s
+}
+
+func TestListZones(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	zones, err := p.ListZones(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(zones, []string{"example.com.", "example.org."}) {
+		t.Fatalf("unexpected zones %v", zones)
+	}
+}
+
+func TestListRecordSets(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	recs, err := p.ListRecordSets(context.Background(), "example.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recs) != 2 {
+		t.Fatalf("expected SOA to be skipped, got %d record sets", len(recs))
+	}
+
+	www := provider.FindRecordSet("www.example.com.", "A", recs)
+	if www == nil || www.TTL != 300 || !slices.Equal(www.Values, []string{"10.0.0.1", "10.0.0.2"}) {
+		t.Fatalf("unexpected record set %+v", www)
+	}
+
+	if www.IsDisabled("10.0.0.1") || !www.IsDisabled("10.0.0.2") {
+		t.Fatalf("unexpected disabled attributes %v", www.Attributes)
+	}
+
+	if www.Attributes["10.0.0.1"][commentsAttribute] == "" {
+		t.Fatal("expected comments to be recorded")
+	}
+}
+
+func TestApplyChange(t *testing.T) {
+	p, s := newTestProvider(t)
+
+	recs, err := p.ListRecordSets(context.Background(), "example.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	www := provider.FindRecordSet("www.example.com.", "A", recs)
+	api := provider.FindRecordSet("api.example.com.", "A", recs)
+
+	changes := []*provider.Change{
+		{Before: www, After: www.WithValues([]string{"10.0.0.2", "10.0.0.9"})},
+		{Before: api},
+	}
+	for _, c := range changes {
+		if err := p.ApplyChange(context.Background(), "example.com.", c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	patches := s.patches["example.com."]
+	if len(patches) != 2 {
+		t.Fatalf("expected 2 RRsets to be patched, got %d", len(patches))
+	}
+
+	replaced := patches[0]
+	want := []record{{Content: "10.0.0.2", Disabled: true}, {Content: "10.0.0.9"}}
+	if replaced.ChangeType != changeTypeReplace || replaced.TTL != 300 || !slices.Equal(replaced.Records, want) {
+		t.Fatalf("unexpected RRset replaced %+v", replaced)
+	}
+
+	if len(replaced.Comments) != 1 || replaced.Comments[0].Content != "web servers" {
+		t.Fatalf("expected comments to be kept, got %+v", replaced.Comments)
+	}
+
+	deleted := patches[1]
+	if deleted.ChangeType != changeTypeDelete || deleted.Name != "api.example.com." {
+		t.Fatalf("unexpected RRset deleted %+v", deleted)
+	}
+}
+
+func TestApplyChangeRestoresComments(t *testing.T) {
+	p, s := newTestProvider(t)
+
+	after := &provider.RecordSet{
+		Name:   "mail.example.com.",
+		Type:   "A",
+		Values: []string{"10.0.0.5"},
+		Attributes: map[string]map[string]string{
+			"10.0.0.5": {provider.DisabledAttribute: "false", commentsAttribute: `[{"content":"mail","account":"ops"}]`},
+		},
+	}
+
+	err := p.ApplyChange(context.Background(), "example.com.", &provider.Change{After: after})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := s.patches["example.com."][0]
+	if created.TTL != defaultTTL || len(created.Comments) != 1 || created.Comments[0].Content != "mail" {
+		t.Fatalf("unexpected RRset created %+v", created)
+	}
+}
+
+func TestInvalidApiKey(t *testing.T) {
+	p, _ := newTestProvider(t)
+	p.client.apiKey = "wrong"
+
+	_, err := p.ListZones(context.Background())
+	if err == nil {
+		t.Fatal("expected error for invalid API key")
+	}
+}
@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import "context"
+
+// Batch collects the changes of a zone to apply them at once
+type Batch struct {
+	updater *Updater
+	zone    string
+	changes []*Change
+}
+
+// NewBatch returns a batch for changes in the given zone
+func (u *Updater) NewBatch(zone string) *Batch {
+	return &Batch{
+		updater: u,
+		zone:    zone,
+		changes: make([]*Change, 0),
+	}
+}
+
+// UpdateRecordSet adds the replacement of the record set to the batch. It returns true if a change was added.
+func (b *Batch) UpdateRecordSet(ctx context.Context, rec *RecordSet, updated *RecordSet) (bool, error) {
+	c, err := b.updater.prepareChange(ctx, rec, updated)
+	if err != nil || c == nil {
+		return false, err
+	}
+
+	b.changes = append(b.changes, c)
+	return true, nil
+}
+
+// Commit applies all changes of the batch. It returns the number of changes applied (in the order they were added).
+// Providers not implementing BatchProvider get the changes one by one until an error occurs.
+func (b *Batch) Commit(ctx context.Context) (int, error) {
+	if b.updater.dryRun || len(b.changes) == 0 {
+		return len(b.changes), nil
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	if p, ok := b.updater.provider.(BatchProvider); ok {
+		return p.ApplyChanges(ctx, b.zone, b.changes)
+	}
+
+	for i, c := range b.changes {
+		err := b.updater.provider.ApplyChange(ctx, b.zone, c)
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(b.changes), nil
+}
@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import "context"
+
+// Provider is the interface a DNS backend has to implement to be drained and undrained
+type Provider interface {
+	// Name returns the identifier of the provider used in the changelog
+	Name() string
+
+	// ListZones returns the names of all zones managed by the provider
+	ListZones(ctx context.Context) ([]string, error)
+
+	// ListRecordSets returns all record sets in the given zone
+	ListRecordSets(ctx context.Context, zone string) ([]*RecordSet, error)
+
+	// ApplyChange replaces a record set in the given zone
+	ApplyChange(ctx context.Context, zone string, change *Change) error
+}
+
+// ProjectProvider is implemented by providers managing zones in the scope of a project (e.g. Google Cloud)
+type ProjectProvider interface {
+	Project() string
+}
+
+// BatchProvider is implemented by providers able to apply multiple changes in a zone at once (e.g. Google Cloud)
+type BatchProvider interface {
+	// ApplyChanges applies the changes in as few atomic operations as possible.
+	// It returns the number of changes applied (in order) before an error occurred.
+	ApplyChanges(ctx context.Context, zone string, changes []*Change) (int, error)
+}
+
+// Waiter is implemented by providers applying changes asynchronously (e.g. Google Cloud)
+type Waiter interface {
+	// WaitForChanges blocks until all changes applied so far are completed or ctx is done.
+	// It returns the record sets of changes not completed.
+	WaitForChanges(ctx context.Context) []*IncompleteChange
+}
+
+// IncompleteChange is a change of a record set not completed by the provider
+type IncompleteChange struct {
+	Zone       string
+	Record     string
+	RecordType string
+	Err        error
+}
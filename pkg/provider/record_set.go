@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// DisabledAttribute marks values not served by the DNS server (e.g. disabled records in PowerDNS)
+const DisabledAttribute = "disabled"
+
+type RecordSet struct {
+	Name   string
+	Type   string
+	TTL    int64
+	Values []string
+
+	// Attributes holds provider specific attributes per value (e.g. proxied flag)
+	Attributes map[string]map[string]string
+
+	// RoutingPolicy holds the provider specific routing policy (if any)
+	RoutingPolicy json.RawMessage
+
+	// Raw holds the provider specific representation of the record set (if any)
+	Raw any
+}
+
+// Change describes the replacement of a record set. Before is nil if the record set does not exist yet,
+// After is nil if the record set should be removed.
+type Change struct {
+	Before *RecordSet
+	After  *RecordSet
+}
+
+func (r *RecordSet) WithValues(values []string) *RecordSet {
+	c := *r
+	c.Values = values
+	return &c
+}
+
+// IsDisabled checks if the value is not served by the DNS server
+func (r *RecordSet) IsDisabled(value string) bool {
+	return r.Attributes[value][DisabledAttribute] == "true"
+}
+
+// FindRecordSet returns the record set with the name and type (nil if not found)
+func FindRecordSet(name, recordType string, records []*RecordSet) *RecordSet {
+	for _, r := range records {
+		if r.Name == name && r.Type == recordType {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// SameValues checks if both slices contain the same values ignoring the order
+func SameValues(a, b []string) bool {
+	x := slices.Clone(a)
+	y := slices.Clone(b)
+	slices.Sort(x)
+	slices.Sort(y)
+
+	return slices.Equal(x, y)
+}
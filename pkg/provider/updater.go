@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"log"
+	"reflect"
+	"sync/atomic"
+)
+
+// ErrLimitReached is returned if the max number of changes is exceeded
+var ErrLimitReached = errors.New("limit of changes reached")
+
+// Updater applies changes to record sets honoring dry run and limit settings
+type Updater struct {
+	provider Provider
+	dryRun   bool
+	limit    int64
+	counter  int64
+}
+
+func NewUpdater(p Provider, dryRun bool, limit int64) *Updater {
+	return &Updater{
+		provider: p,
+		dryRun:   dryRun,
+		limit:    limit,
+	}
+}
+
+// UpdateRecordSet replaces the record set by the updated one. It returns true if the change was applied (or simulated).
+// No change is started after ctx is canceled, but a change already sent to the provider is not interrupted.
+func (u *Updater) UpdateRecordSet(ctx context.Context, zone string, rec *RecordSet, updated *RecordSet) (bool, error) {
+	c, err := u.prepareChange(ctx, rec, updated)
+	if err != nil || c == nil {
+		return false, err
+	}
+
+	if u.dryRun {
+		return true, nil
+	}
+
+	err = u.provider.ApplyChange(context.WithoutCancel(ctx), zone, c)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// prepareChange checks the limit and logs the change. It returns nil if the record set is not changed.
+func (u *Updater) prepareChange(ctx context.Context, rec *RecordSet, updated *RecordSet) (*Change, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	values := updated.Values
+	if reflect.DeepEqual(rec.Values, values) {
+		return nil, nil
+	}
+
+	count := atomic.AddInt64(&u.counter, 1)
+	if u.limit >= 0 && count > u.limit {
+		return nil, ErrLimitReached
+	}
+
+	if len(rec.Values) > 0 {
+		log.Printf("- %s: %s %s\n", rec.Name, rec.Type, rec.Values)
+	}
+
+	if len(values) > 0 {
+		log.Printf("+ %s: %s %s\n", rec.Name, rec.Type, values)
+	}
+
+	c := &Change{}
+	if len(rec.Values) > 0 {
+		c.Before = rec
+	}
+
+	if len(values) > 0 {
+		c.After = updated
+	}
+
+	return c, nil
+}
@@ -0,0 +1,29 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// WaitForChanges waits up to timeout for the changes applied by p to complete.
+// Providers not implementing Waiter complete changes synchronously, so there is nothing to wait for.
+func WaitForChanges(ctx context.Context, p Provider, timeout time.Duration) []*IncompleteChange {
+	w, ok := p.(Waiter)
+	if !ok {
+		return nil
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	log.Println("Waiting for changes to complete...")
+	return w.WaitForChanges(ctx)
+}
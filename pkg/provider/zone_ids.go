@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ZoneIDs maps zone names to the provider specific zone IDs
+type ZoneIDs struct {
+	mutex sync.Mutex
+	ids   map[string]string
+}
+
+func NewZoneIDs() *ZoneIDs {
+	return &ZoneIDs{ids: make(map[string]string)}
+}
+
+// Add stores the ID of the zone
+func (z *ZoneIDs) Add(zone, id string) {
+	z.mutex.Lock()
+	defer z.mutex.Unlock()
+
+	z.ids[zone] = id
+}
+
+// Get returns the ID of the zone. If the zone is unknown, the zones of the provider are listed to learn its ID.
+func (z *ZoneIDs) Get(ctx context.Context, zone string, p Provider) (string, error) {
+	if id, found := z.lookup(zone); found {
+		return id, nil
+	}
+
+	_, err := p.ListZones(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	id, found := z.lookup(zone)
+	if !found {
+		return "", fmt.Errorf("zone %s not found", zone)
+	}
+
+	return id, nil
+}
+
+func (z *ZoneIDs) lookup(zone string) (string, bool) {
+	z.mutex.Lock()
+	defer z.mutex.Unlock()
+
+	id, found := z.ids[zone]
+	return id, found
+}
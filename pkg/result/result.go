@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package result
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Status summarizes the outcome of a drain or undrain
+type Status int
+
+const (
+	// Success means no error occurred
+	Success Status = iota
+
+	// PartialFailure means some records were changed but errors occurred
+	PartialFailure
+
+	// TotalFailure means errors occurred and no record was changed
+	TotalFailure
+)
+
+// Result collects statistics and errors of a drain or undrain run. It is safe for concurrent use.
+type Result struct {
+	mutex             sync.Mutex
+	ZonesScanned      int64      `json:"zonesScanned"`
+	ZonesFailed       int64      `json:"zonesFailed"`
+	RecordSetsScanned int64      `json:"recordSetsScanned"`
+	RecordsChanged    int64      `json:"recordsChanged"`
+	RecordsSkipped    int64      `json:"recordsSkipped"`
+	RecordsFailed     int64      `json:"recordsFailed"`
+	Failures          []*Failure `json:"failures,omitempty"`
+
+	// Planned is set if the changes were written to a plan instead of being applied
+	Planned bool `json:"planned,omitempty"`
+}
+
+// Failure describes an error in a zone or for a specific record
+type Failure struct {
+	Zone       string `json:"zone"`
+	Record     string `json:"record,omitempty"`
+	RecordType string `json:"recordType,omitempty"`
+	Error      string `json:"error"`
+}
+
+func NewResult() *Result {
+	return &Result{Failures: make([]*Failure, 0)}
+}
+
+func (r *Result) AddZone(recordSets int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.ZonesScanned++
+	r.RecordSetsScanned += int64(recordSets)
+}
+
+func (r *Result) AddZoneFailure(zone string, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.ZonesFailed++
+	r.Failures = append(r.Failures, &Failure{Zone: zone, Error: err.Error()})
+}
+
+func (r *Result) AddChanged() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.RecordsChanged++
+}
+
+func (r *Result) AddSkipped() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.RecordsSkipped++
+}
+
+func (r *Result) AddRecordFailure(zone, record, recordType string, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.RecordsFailed++
+	r.Failures = append(r.Failures, &Failure{Zone: zone, Record: record, RecordType: recordType, Error: err.Error()})
+}
+
+func (r *Result) Status() Status {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.ZonesFailed == 0 && r.RecordsFailed == 0 {
+		return Success
+	}
+
+	if r.RecordsChanged == 0 {
+		return TotalFailure
+	}
+
+	return PartialFailure
+}
+
+func (r *Result) String() string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	changed := "changed"
+	if r.Planned {
+		changed = "planned"
+	}
+
+	return fmt.Sprintf("zones: %d scanned, %d failed; record sets: %d scanned, %d %s, %d skipped, %d failed",
+		r.ZonesScanned, r.ZonesFailed, r.RecordSetsScanned, r.RecordsChanged, changed, r.RecordsSkipped, r.RecordsFailed)
+}
@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package result
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		name    string
+		planned bool
+		want    string
+	}{
+		{
+			name: "applied",
+			want: "zones: 1 scanned, 0 failed; record sets: 10 scanned, 2 changed, 1 skipped, 0 failed",
+		},
+		{
+			name:    "planned",
+			planned: true,
+			want:    "zones: 1 scanned, 0 failed; record sets: 10 scanned, 2 planned, 1 skipped, 0 failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewResult()
+			r.AddZone(10)
+			r.AddChanged()
+			r.AddChanged()
+			r.AddSkipped()
+			r.Planned = test.planned
+
+			if got := r.String(); got != test.want {
+				t.Fatalf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name   string
+		result func(*Result)
+		want   Status
+	}{
+		{
+			name:   "nothing changed",
+			result: func(r *Result) { r.AddZone(1) },
+			want:   Success,
+		},
+		{
+			name: "records changed and skipped",
+			result: func(r *Result) {
+				r.AddChanged()
+				r.AddSkipped()
+			},
+			want: Success,
+		},
+		{
+			name: "zone failed after records were changed",
+			result: func(r *Result) {
+				r.AddChanged()
+				r.AddZoneFailure("example.org.", errFailed)
+			},
+			want: PartialFailure,
+		},
+		{
+			name: "record failed after records were changed",
+			result: func(r *Result) {
+				r.AddChanged()
+				r.AddRecordFailure("example.com.", "www.example.com.", "A", errFailed)
+			},
+			want: PartialFailure,
+		},
+		{
+			name:   "zone failed",
+			result: func(r *Result) { r.AddZoneFailure("example.org.", errFailed) },
+			want:   TotalFailure,
+		},
+		{
+			name:   "record failed",
+			result: func(r *Result) { r.AddRecordFailure("example.com.", "www.example.com.", "A", errFailed) },
+			want:   TotalFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewResult()
+			test.result(r)
+
+			if got := r.Status(); got != test.want {
+				t.Fatalf("expected status %d, got %d", test.want, got)
+			}
+		})
+	}
+}
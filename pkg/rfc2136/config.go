@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package rfc2136
+
+import (
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+type Config struct {
+	Server        string
+	Zones         []string
+	TsigKey       string
+	TsigSecret    string
+	TsigAlgorithm string
+}
+
+func (c Config) useTsig() bool {
+	return c.TsigKey != ""
+}
+
+func (c Config) tsigSecrets() map[string]string {
+	if !c.useTsig() {
+		return nil
+	}
+
+	return map[string]string{dns.Fqdn(c.TsigKey): c.TsigSecret}
+}
+
+func (c Config) tsigAlgorithm() string {
+	if c.TsigAlgorithm == "" {
+		return dns.HmacSHA256
+	}
+
+	return dns.Fqdn(c.TsigAlgorithm)
+}
+
+func (c Config) sign(m *dns.Msg) {
+	if c.useTsig() {
+		m.SetTsig(dns.Fqdn(c.TsigKey), c.tsigAlgorithm(), 300, time.Now().Unix())
+	}
+}
@@ -0,0 +1,218 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package rfc2136
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+const (
+	providerName = "rfc2136"
+	defaultTTL   = 3600
+	dialTimeout  = 2 * time.Second
+)
+
+// DynamicUpdateProvider reads zones using AXFR and modifies them by RFC 2136 dynamic updates
+type DynamicUpdateProvider struct {
+	cfg Config
+}
+
+func NewProvider(cfg Config) *DynamicUpdateProvider {
+	return &DynamicUpdateProvider{cfg: cfg}
+}
+
+func (p *DynamicUpdateProvider) Name() string {
+	return providerName
+}
+
+func (p *DynamicUpdateProvider) ListZones(ctx context.Context) ([]string, error) {
+	zones := make([]string, 0, len(p.cfg.Zones))
+	for _, z := range p.cfg.Zones {
+		zones = append(zones, dns.Fqdn(z))
+	}
+
+	return zones, nil
+}
+
+func (p *DynamicUpdateProvider) ListRecordSets(ctx context.Context, zone string) ([]*provider.RecordSet, error) {
+	m := &dns.Msg{}
+	m.SetAxfr(dns.Fqdn(zone))
+	p.cfg.sign(m)
+
+	d := &net.Dialer{Timeout: dialTimeout}
+	c, err := d.DialContext(ctx, "tcp", p.cfg.Server)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &dns.Conn{Conn: c}
+	defer conn.Close()
+
+	// closing the connection aborts a transfer still running when the context is done
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	t := &dns.Transfer{Conn: conn, TsigSecret: p.cfg.tsigSecrets()}
+
+	ch, err := t.In(m, p.cfg.Server)
+	if err != nil {
+		return nil, transferError(ctx, err)
+	}
+
+	recs := make([]*provider.RecordSet, 0)
+	index := make(map[recordKey]*provider.RecordSet)
+	for env := range ch {
+		if env.Error != nil {
+			return nil, fmt.Errorf("zone transfer failed: %w", transferError(ctx, env.Error))
+		}
+
+		for _, rr := range env.RR {
+			if !isDrainable(rr) {
+				continue
+			}
+
+			key := recordKey{name: rr.Header().Name, rrType: rr.Header().Rrtype}
+			rec, found := index[key]
+			if !found {
+				rec = &provider.RecordSet{
+					Name:   rr.Header().Name,
+					Type:   dns.TypeToString[rr.Header().Rrtype],
+					TTL:    int64(rr.Header().Ttl),
+					Values: make([]string, 0),
+				}
+				index[key] = rec
+				recs = append(recs, rec)
+			}
+
+			rec.Values = append(rec.Values, rdata(rr))
+		}
+	}
+
+	return recs, nil
+}
+
+// transferError prefers the error of the context when the transfer was aborted by it
+func transferError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return err
+}
+
+func (p *DynamicUpdateProvider) ApplyChange(ctx context.Context, zone string, change *provider.Change) error {
+	m := &dns.Msg{}
+	m.SetUpdate(dns.Fqdn(zone))
+
+	removed, added := diffChange(change)
+
+	rrs, err := toRRs(change.Before, removed)
+	if err != nil {
+		return err
+	}
+	if len(rrs) > 0 {
+		m.Remove(rrs)
+	}
+
+	rrs, err = toRRs(change.After, added)
+	if err != nil {
+		return err
+	}
+	if len(rrs) > 0 {
+		m.Insert(rrs)
+	}
+
+	p.cfg.sign(m)
+
+	c := &dns.Client{TsigSecret: p.cfg.tsigSecrets()}
+	r, _, err := c.ExchangeContext(ctx, m, p.cfg.Server)
+	if err != nil {
+		return err
+	}
+
+	if r.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("update rejected by server: %s", dns.RcodeToString[r.Rcode])
+	}
+
+	return nil
+}
+
+type recordKey struct {
+	name   string
+	rrType uint16
+}
+
+func isDrainable(rr dns.RR) bool {
+	switch rr.Header().Rrtype {
+	case dns.TypeSOA, dns.TypeRRSIG, dns.TypeNSEC, dns.TypeNSEC3, dns.TypeNSEC3PARAM, dns.TypeDNSKEY:
+		return false
+	default:
+		return true
+	}
+}
+
+func rdata(rr dns.RR) string {
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
+
+func diffChange(change *provider.Change) (removed []string, added []string) {
+	before := make(map[string]bool)
+	if change.Before != nil {
+		for _, v := range change.Before.Values {
+			before[v] = true
+		}
+	}
+
+	after := make(map[string]bool)
+	if change.After != nil {
+		for _, v := range change.After.Values {
+			after[v] = true
+			if !before[v] {
+				added = append(added, v)
+			}
+		}
+	}
+
+	if change.Before != nil {
+		for _, v := range change.Before.Values {
+			if !after[v] {
+				removed = append(removed, v)
+			}
+		}
+	}
+
+	return removed, added
+}
+
+func toRRs(rec *provider.RecordSet, values []string) ([]dns.RR, error) {
+	if rec == nil {
+		return nil, nil
+	}
+
+	ttl := rec.TTL
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+
+	rrs := make([]dns.RR, 0, len(values))
+	for _, v := range values {
+		rr, err := dns.NewRR(fmt.Sprintf("%s %d IN %s %s", dns.Fqdn(rec.Name), ttl, rec.Type, v))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s %s %s: %w", rec.Name, rec.Type, v, err)
+		}
+
+		rrs = append(rrs, rr)
+	}
+
+	return rrs, nil
+}
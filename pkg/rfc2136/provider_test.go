@@ -0,0 +1,292 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package rfc2136
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+const (
+	testZone   = "example.com."
+	testKey    = "drain."
+	testSecret = "c2VjcmV0c2VjcmV0c2VjcmV0"
+)
+
+// testServer is an authoritative server for a single zone accepting AXFR and dynamic updates signed with the test key
+type testServer struct {
+	mutex sync.Mutex
+	rrs   []dns.RR
+	addr  string
+}
+
+func startTestServer(t *testing.T, records ...string) *testServer {
+	t.Helper()
+
+	s := &testServer{}
+	for _, r := range records {
+		rr, err := dns.NewRR(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.rrs = append(s.rrs, rr)
+	}
+
+	pc, l := listen(t)
+	s.addr = pc.LocalAddr().String()
+
+	secrets := map[string]string{testKey: testSecret}
+	for _, srv := range []*dns.Server{
+		{PacketConn: pc, Handler: s, TsigSecret: secrets, MsgAcceptFunc: acceptUpdates},
+		{Listener: l, Handler: s, TsigSecret: secrets, MsgAcceptFunc: acceptUpdates},
+	} {
+		started := make(chan struct{})
+		srv.NotifyStartedFunc = func() { close(started) }
+		go srv.ActivateAndServe()
+		<-started
+		t.Cleanup(func() { srv.Shutdown() })
+	}
+
+	return s
+}
+
+// acceptUpdates accepts dynamic updates rejected by the default accept func
+func acceptUpdates(h dns.Header) dns.MsgAcceptAction {
+	if int(h.Bits>>11)&0xF == dns.OpcodeUpdate {
+		return dns.MsgAccept
+	}
+
+	return dns.DefaultMsgAcceptFunc(h)
+}
+
+// listen opens UDP and TCP sockets on the same port
+func listen(t *testing.T) (net.PacketConn, net.Listener) {
+	t.Helper()
+
+	for range 10 {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		l, err := net.Listen("tcp", pc.LocalAddr().String())
+		if err == nil {
+			return pc, l
+		}
+
+		pc.Close()
+	}
+
+	t.Fatal("could not listen on UDP and TCP port")
+	return nil, nil
+}
+
+func (s *testServer) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	m := &dns.Msg{}
+	m.SetReply(r)
+
+	if r.IsTsig() == nil || w.TsigStatus() != nil {
+		m.SetRcode(r, dns.RcodeNotAuth)
+		w.WriteMsg(m)
+		return
+	}
+
+	s.mutex.Lock()
+	switch {
+	case r.Opcode == dns.OpcodeUpdate:
+		s.update(r.Ns)
+	case len(r.Question) == 1 && r.Question[0].Qtype == dns.TypeAXFR:
+		m.Answer = s.transfer()
+	default:
+		m.SetRcode(r, dns.RcodeRefused)
+	}
+	s.mutex.Unlock()
+
+	m.SetTsig(testKey, dns.HmacSHA256, 300, time.Now().Unix())
+	w.WriteMsg(m)
+}
+
+func (s *testServer) transfer() []dns.RR {
+	soa := s.rrs[0]
+	return append(slices.Clone(s.rrs), soa)
+}
+
+func (s *testServer) update(rrs []dns.RR) {
+	for _, rr := range rrs {
+		if rr.Header().Class == dns.ClassNONE {
+			s.rrs = slices.DeleteFunc(s.rrs, func(x dns.RR) bool {
+				return x.Header().Name == rr.Header().Name && x.Header().Rrtype == rr.Header().Rrtype && rdata(x) == rdata(rr)
+			})
+			continue
+		}
+
+		s.rrs = append(s.rrs, rr)
+	}
+}
+
+func (s *testServer) values(name string, rrType uint16) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	values := make([]string, 0)
+	for _, rr := range s.rrs {
+		if rr.Header().Name == name && rr.Header().Rrtype == rrType {
+			values = append(values, rdata(rr))
+		}
+	}
+
+	return values
+}
+
+func newTestServerWithZone(t *testing.T) *testServer {
+	return startTestServer(t,
+		"example.com. 3600 IN SOA ns1.example.com. hostmaster.example.com. 1 3600 900 604800 300",
+		"example.com. 3600 IN NS ns1.example.com.",
+		"www.example.com. 300 IN A 10.0.0.1",
+		"www.example.com. 300 IN A 10.0.0.2",
+		"example.com. 3600 IN MX 10 mx1.example.com.",
+	)
+}
+
+func testConfig(s *testServer) Config {
+	return Config{
+		Server:     s.addr,
+		Zones:      []string{"example.com"},
+		TsigKey:    "drain",
+		TsigSecret: testSecret,
+	}
+}
+
+func TestListRecordSets(t *testing.T) {
+	s := newTestServerWithZone(t)
+	p := NewProvider(testConfig(s))
+
+	recs, err := p.ListRecordSets(context.Background(), testZone)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recs) != 3 {
+		t.Fatalf("expected 3 record sets (SOA excluded), got %d", len(recs))
+	}
+
+	byName := make(map[string]*provider.RecordSet)
+	for _, r := range recs {
+		byName[r.Name+" "+r.Type] = r
+	}
+
+	www := byName["www.example.com. A"]
+	if www == nil {
+		t.Fatal("record set www.example.com. A not found")
+	}
+
+	if www.TTL != 300 || !slices.Equal(www.Values, []string{"10.0.0.1", "10.0.0.2"}) {
+		t.Fatalf("unexpected record set %+v", www)
+	}
+
+	mx := byName["example.com. MX"]
+	if mx == nil || !slices.Equal(mx.Values, []string{"10 mx1.example.com."}) {
+		t.Fatalf("unexpected MX record set %+v", mx)
+	}
+}
+
+func TestApplyChange(t *testing.T) {
+	s := newTestServerWithZone(t)
+	p := NewProvider(testConfig(s))
+
+	before := &provider.RecordSet{Name: "www.example.com.", Type: "A", TTL: 300, Values: []string{"10.0.0.1", "10.0.0.2"}}
+	err := p.ApplyChange(context.Background(), testZone, &provider.Change{
+		Before: before,
+		After:  before.WithValues([]string{"10.0.0.2", "10.0.0.3"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := s.values("www.example.com.", dns.TypeA)
+	if !slices.Equal(got, []string{"10.0.0.2", "10.0.0.3"}) {
+		t.Fatalf("unexpected values after update: %v", got)
+	}
+}
+
+func TestTsigRequired(t *testing.T) {
+	s := newTestServerWithZone(t)
+
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{
+			name: "unsigned",
+			cfg:  Config{Server: s.addr, Zones: []string{"example.com"}},
+		},
+		{
+			name: "wrong secret",
+			cfg:  Config{Server: s.addr, Zones: []string{"example.com"}, TsigKey: "drain", TsigSecret: "d3Jvbmd3cm9uZw=="},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := NewProvider(test.cfg)
+
+			_, err := p.ListRecordSets(context.Background(), testZone)
+			if err == nil {
+				t.Fatal("expected zone transfer to fail")
+			}
+
+			rec := &provider.RecordSet{Name: "www.example.com.", Type: "A", TTL: 300, Values: []string{"10.0.0.1"}}
+			err = p.ApplyChange(context.Background(), testZone, &provider.Change{Before: rec, After: rec.WithValues([]string{})})
+			if err == nil {
+				t.Fatal("expected update to fail")
+			}
+
+			if got := s.values("www.example.com.", dns.TypeA); len(got) != 2 {
+				t.Fatalf("expected records to be unchanged, got %v", got)
+			}
+		})
+	}
+}
+
+func TestListRecordSetsCanceled(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	// accept the connection but never answer the transfer
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		t.Cleanup(func() { c.Close() })
+	}()
+
+	p := NewProvider(Config{Server: l.Addr().String(), Zones: []string{"example.com"}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = p.ListRecordSets(ctx, testZone)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("expected transfer to be aborted by the context, took %s", d)
+	}
+}
@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package route53
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	r53 "github.com/aws/aws-sdk-go-v2/service/route53"
+)
+
+type Config struct {
+	Profile  string
+	Region   string
+	Endpoint string
+}
+
+func (c Config) newClient(ctx context.Context) (*r53.Client, error) {
+	opts := make([]func(*awsconfig.LoadOptions) error, 0)
+	if c.Profile != "" {
+		opts = append(opts, awsconfig.WithSharedConfigProfile(c.Profile))
+	}
+
+	if c.Region != "" {
+		opts = append(opts, awsconfig.WithRegion(c.Region))
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return r53.NewFromConfig(cfg, func(o *r53.Options) {
+		if c.Endpoint != "" {
+			o.BaseEndpoint = aws.String(c.Endpoint)
+		}
+	}), nil
+}
@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package route53
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	r53 "github.com/aws/aws-sdk-go-v2/service/route53"
+	r53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+const providerName = "route53"
+
+// Route53Provider provides access to hosted zones in AWS Route 53
+type Route53Provider struct {
+	client *r53.Client
+	zones  *provider.ZoneIDs
+}
+
+func NewProvider(ctx context.Context, cfg Config) (*Route53Provider, error) {
+	client, err := cfg.newClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Route53Provider{
+		client: client,
+		zones:  provider.NewZoneIDs(),
+	}, nil
+}
+
+func (p *Route53Provider) Name() string {
+	return providerName
+}
+
+// ListZones returns the names of all hosted zones. If the same name is used by more than one hosted zone
+// (e.g. public and private zone) the zone ID is appended to the name to keep it unique.
+func (p *Route53Provider) ListZones(ctx context.Context) ([]string, error) {
+	hostedZones := make([]r53types.HostedZone, 0)
+	pg := r53.NewListHostedZonesPaginator(p.client, &r53.ListHostedZonesInput{})
+	for pg.HasMorePages() {
+		r, err := pg.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		hostedZones = append(hostedZones, r.HostedZones...)
+	}
+
+	count := make(map[string]int)
+	for _, z := range hostedZones {
+		count[aws.ToString(z.Name)]++
+	}
+
+	zones := make([]string, 0, len(hostedZones))
+	for _, z := range hostedZones {
+		name := aws.ToString(z.Name)
+		id := strings.TrimPrefix(aws.ToString(z.Id), "/hostedzone/")
+		if count[name] > 1 {
+			name = fmt.Sprintf("%s@%s", name, id)
+		}
+
+		p.zones.Add(name, id)
+		zones = append(zones, name)
+	}
+
+	return zones, nil
+}
+
+func (p *Route53Provider) ListRecordSets(ctx context.Context, zone string) ([]*provider.RecordSet, error) {
+	id, err := p.zones.Get(ctx, zone, p)
+	if err != nil {
+		return nil, err
+	}
+
+	recs := make([]*provider.RecordSet, 0)
+	pg := r53.NewListResourceRecordSetsPaginator(p.client, &r53.ListResourceRecordSetsInput{HostedZoneId: aws.String(id)})
+	for pg.HasMorePages() {
+		r, err := pg.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rec := range r.ResourceRecordSets {
+			if rec.AliasTarget != nil {
+				continue
+			}
+
+			if rec.SetIdentifier != nil {
+				log.Printf("WARN - %s %s: Record sets with routing policy are not supported. Skipping.\n", rec.Type, aws.ToString(rec.Name))
+				continue
+			}
+
+			recs = append(recs, toRecordSet(rec))
+		}
+	}
+
+	return recs, nil
+}
+
+func (p *Route53Provider) ApplyChange(ctx context.Context, zone string, change *provider.Change) error {
+	id, err := p.zones.Get(ctx, zone, p)
+	if err != nil {
+		return err
+	}
+
+	changes := make([]r53types.Change, 0)
+	if change.Before != nil {
+		changes = append(changes, r53types.Change{
+			Action:            r53types.ChangeActionDelete,
+			ResourceRecordSet: fromRecordSet(change.Before),
+		})
+	}
+
+	if change.After != nil {
+		changes = append(changes, r53types.Change{
+			Action:            r53types.ChangeActionCreate,
+			ResourceRecordSet: fromRecordSet(change.After),
+		})
+	}
+
+	_, err = p.client.ChangeResourceRecordSets(ctx, &r53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(id),
+		ChangeBatch:  &r53types.ChangeBatch{Changes: changes},
+	})
+	return err
+}
+
+func toRecordSet(rec r53types.ResourceRecordSet) *provider.RecordSet {
+	values := make([]string, 0, len(rec.ResourceRecords))
+	for _, r := range rec.ResourceRecords {
+		values = append(values, aws.ToString(r.Value))
+	}
+
+	return &provider.RecordSet{
+		Name:   aws.ToString(rec.Name),
+		Type:   string(rec.Type),
+		TTL:    aws.ToInt64(rec.TTL),
+		Values: values,
+		Raw:    rec,
+	}
+}
+
+func fromRecordSet(rec *provider.RecordSet) *r53types.ResourceRecordSet {
+	res := r53types.ResourceRecordSet{}
+	if raw, ok := rec.Raw.(r53types.ResourceRecordSet); ok {
+		res = raw
+	}
+
+	res.Name = aws.String(rec.Name)
+	res.Type = r53types.RRType(rec.Type)
+	res.TTL = aws.Int64(rec.TTL)
+	res.ResourceRecords = make([]r53types.ResourceRecord, 0, len(rec.Values))
+	for _, v := range rec.Values {
+		res.ResourceRecords = append(res.ResourceRecords, r53types.ResourceRecord{Value: aws.String(v)})
+	}
+
+	return &res
+}
@@ -0,0 +1,206 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package route53
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+const apiPrefix = "/2013-04-01/hostedzone"
+
+type changeRequest struct {
+	Changes []struct {
+		Action            string
+		ResourceRecordSet struct {
+			Name            string
+			Type            string
+			TTL             int64
+			ResourceRecords []struct {
+				Value string
+			} `xml:"ResourceRecords>ResourceRecord"`
+		}
+	} `xml:"ChangeBatch>Changes>Change"`
+}
+
+// standIn answers the Route 53 API calls used by the provider
+type standIn struct {
+	mutex   sync.Mutex
+	zones   string
+	rrsets  map[string]string
+	changes map[string]changeRequest
+}
+
+func (s *standIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	w.Header().Set("Content-Type", "text/xml")
+
+	switch {
+	case r.Method == http.MethodGet && path == apiPrefix:
+		fmt.Fprint(w, s.zones)
+	case r.Method == http.MethodGet && strings.HasSuffix(path, "/rrset"):
+		fmt.Fprint(w, s.rrsets[zoneIDFromPath(path)])
+	case r.Method == http.MethodPost && strings.HasSuffix(path, "/rrset"):
+		b, _ := io.ReadAll(r.Body)
+
+		var req changeRequest
+		if err := xml.Unmarshal(b, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.changes[zoneIDFromPath(path)] = req
+
+		fmt.Fprint(w, `<ChangeResourceRecordSetsResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/">
+  <ChangeInfo><Id>/change/C1</Id><Status>PENDING</Status><SubmittedAt>2026-01-01T00:00:00Z</SubmittedAt></ChangeInfo>
+</ChangeResourceRecordSetsResponse>`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func zoneIDFromPath(path string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(path, apiPrefix+"/"), "/rrset")
+}
+
+func newTestProvider(t *testing.T) (*Route53Provider, *standIn) {
+	t.Helper()
+
+	s := &standIn{
+		zones: `<ListHostedZonesResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/">
+  <HostedZones>
+    <HostedZone><Id>/hostedzone/Z1</Id><Name>example.com.</Name><CallerReference>a</CallerReference></HostedZone>
+    <HostedZone><Id>/hostedzone/Z2</Id><Name>example.com.</Name><CallerReference>b</CallerReference></HostedZone>
+    <HostedZone><Id>/hostedzone/Z3</Id><Name>example.org.</Name><CallerReference>c</CallerReference></HostedZone>
+  </HostedZones>
+  <IsTruncated>false</IsTruncated>
+  <MaxItems>100</MaxItems>
+</ListHostedZonesResponse>`,
+		rrsets: map[string]string{
+			"Z3": `<ListResourceRecordSetsResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/">
+  <ResourceRecordSets>
+    <ResourceRecordSet>
+      <Name>www.example.org.</Name><Type>A</Type><TTL>300</TTL>
+      <ResourceRecords>
+        <ResourceRecord><Value>10.0.0.1</Value></ResourceRecord>
+        <ResourceRecord><Value>10.0.0.2</Value></ResourceRecord>
+      </ResourceRecords>
+    </ResourceRecordSet>
+    <ResourceRecordSet>
+      <Name>alias.example.org.</Name><Type>A</Type>
+      <AliasTarget><HostedZoneId>Z3</HostedZoneId><DNSName>www.example.org.</DNSName><EvaluateTargetHealth>false</EvaluateTargetHealth></AliasTarget>
+    </ResourceRecordSet>
+    <ResourceRecordSet>
+      <Name>weighted.example.org.</Name><Type>A</Type><SetIdentifier>one</SetIdentifier><Weight>10</Weight><TTL>300</TTL>
+      <ResourceRecords><ResourceRecord><Value>10.0.0.1</Value></ResourceRecord></ResourceRecords>
+    </ResourceRecordSet>
+  </ResourceRecordSets>
+  <IsTruncated>false</IsTruncated>
+  <MaxItems>300</MaxItems>
+</ListResourceRecordSetsResponse>`,
+		},
+		changes: make(map[string]changeRequest),
+	}
+
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+
+	p, err := NewProvider(context.Background(), Config{Region: "us-east-1", Endpoint: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return p, s
+}
+
+func TestListZones(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	zones, err := p.ListZones(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"example.com.@Z1", "example.com.@Z2", "example.org."}
+	if !slices.Equal(zones, want) {
+		t.Fatalf("expected %v, got %v", want, zones)
+	}
+}
+
+func TestListRecordSets(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	recs, err := p.ListRecordSets(context.Background(), "example.org.")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recs) != 1 {
+		t.Fatalf("expected alias and weighted record sets to be skipped, got %d record sets", len(recs))
+	}
+
+	rec := recs[0]
+	if rec.Name != "www.example.org." || rec.Type != "A" || rec.TTL != 300 || !slices.Equal(rec.Values, []string{"10.0.0.1", "10.0.0.2"}) {
+		t.Fatalf("unexpected record set %+v", rec)
+	}
+}
+
+func TestApplyChange(t *testing.T) {
+	p, s := newTestProvider(t)
+
+	recs, err := p.ListRecordSets(context.Background(), "example.org.")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.ApplyChange(context.Background(), "example.org.", &provider.Change{
+		Before: recs[0],
+		After:  recs[0].WithValues([]string{"10.0.0.2"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, found := s.changes["Z3"]
+	if !found {
+		t.Fatal("no change submitted for hosted zone Z3")
+	}
+
+	if len(req.Changes) != 2 || req.Changes[0].Action != "DELETE" || req.Changes[1].Action != "CREATE" {
+		t.Fatalf("expected DELETE and CREATE, got %+v", req.Changes)
+	}
+
+	created := req.Changes[1].ResourceRecordSet
+	if created.Name != "www.example.org." || created.TTL != 300 || len(created.ResourceRecords) != 1 || created.ResourceRecords[0].Value != "10.0.0.2" {
+		t.Fatalf("unexpected record set created %+v", created)
+	}
+}
+
+func TestUnknownZone(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	_, err := p.ListRecordSets(context.Background(), "example.net.")
+	if err == nil {
+		t.Fatal("expected error for unknown zone")
+	}
+}
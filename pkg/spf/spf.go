@@ -0,0 +1,360 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package spf
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+const maxTxtStringLength = 255
+
+// record is a SPF policy read from the value of a TXT or SPF record
+type record struct {
+	strings []string
+	quoted  bool
+}
+
+// edit replaces the bytes from start to end of the concatenated strings by text
+type edit struct {
+	start int
+	end   int
+	text  string
+}
+
+// IsRecordType checks if records of the type may contain SPF policies
+func IsRecordType(recordType string) bool {
+	return recordType == "TXT" || recordType == "SPF"
+}
+
+// parse parses the value of a TXT or SPF record. Values consisting of multiple character strings are concatenated as defined in RFC 7208.
+func parse(value string) (*record, bool) {
+	rec := &record{}
+
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "\"") {
+		s, ok := parseCharacterStrings(trimmed)
+		if !ok {
+			return nil, false
+		}
+
+		rec.strings = s
+		rec.quoted = true
+	} else {
+		rec.strings = []string{value}
+	}
+
+	text := rec.text()
+	if len(text) < 6 || !strings.EqualFold(text[:6], "v=spf1") || (len(text) > 6 && text[6] != ' ') {
+		return nil, false
+	}
+
+	return rec, true
+}
+
+// parseCharacterStrings parses a sequence of quoted character strings in zone file presentation format
+func parseCharacterStrings(value string) ([]string, bool) {
+	res := make([]string, 0)
+
+	for i := 0; i < len(value); {
+		if value[i] == ' ' || value[i] == '\t' {
+			i++
+			continue
+		}
+
+		if value[i] != '"' {
+			return nil, false
+		}
+
+		var b strings.Builder
+		i++
+		for ; i < len(value) && value[i] != '"'; i++ {
+			if value[i] != '\\' {
+				b.WriteByte(value[i])
+				continue
+			}
+
+			i++
+			if i >= len(value) {
+				return nil, false
+			}
+
+			if i+3 <= len(value) && isDigits(value[i:i+3]) {
+				n, _ := strconv.Atoi(value[i : i+3])
+				b.WriteByte(byte(n))
+				i += 2
+				continue
+			}
+
+			b.WriteByte(value[i])
+		}
+
+		if i >= len(value) {
+			return nil, false
+		}
+
+		res = append(res, b.String())
+		i++
+	}
+
+	return res, len(res) > 0
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *record) text() string {
+	return strings.Join(r.strings, "")
+}
+
+// String returns the record in the format it was read. Strings exceeding the maximum length are split.
+func (r *record) String() string {
+	if !r.quoted {
+		return strings.Join(r.strings, "")
+	}
+
+	parts := make([]string, 0, len(r.strings))
+	for _, s := range r.strings {
+		for len(s) > maxTxtStringLength {
+			parts = append(parts, quoteCharacterString(s[:maxTxtStringLength]))
+			s = s[maxTxtStringLength:]
+		}
+
+		parts = append(parts, quoteCharacterString(s))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func quoteCharacterString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < ' ' || c > '~':
+			b.WriteString("\\" + leftPad(strconv.Itoa(int(c))))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+
+	return b.String()
+}
+
+func leftPad(s string) string {
+	return strings.Repeat("0", 3-len(s)) + s
+}
+
+// apply returns a copy of the record with the edits applied. Each string keeps its part of the text, so the string splitting is preserved.
+func (r *record) apply(edits []edit) *record {
+	res := &record{
+		strings: make([]string, 0, len(r.strings)),
+		quoted:  r.quoted,
+	}
+
+	pos := 0
+	for _, s := range r.strings {
+		var b strings.Builder
+		for i := 0; i < len(s); i++ {
+			skip := false
+			for _, e := range edits {
+				if e.start == pos+i {
+					b.WriteString(e.text)
+				}
+
+				if pos+i >= e.start && pos+i < e.end {
+					skip = true
+				}
+			}
+
+			if !skip {
+				b.WriteByte(s[i])
+			}
+		}
+
+		pos += len(s)
+		if b.Len() > 0 {
+			res.strings = append(res.strings, b.String())
+		}
+	}
+
+	return res
+}
+
+// mechanism is an ip4 or ip6 mechanism of a SPF policy
+type mechanism struct {
+	start     int
+	end       int
+	qualifier string
+	ipNet     *net.IPNet
+}
+
+// ipMechanisms returns the ip4 and ip6 mechanisms of the policy
+func (r *record) ipMechanisms() []mechanism {
+	text := r.text()
+	res := make([]mechanism, 0)
+
+	start := -1
+	for i := 0; i <= len(text); i++ {
+		if i < len(text) && text[i] != ' ' {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+
+		if start >= 0 {
+			if m, ok := parseIPMechanism(text[start:i]); ok {
+				m.start = start
+				m.end = i
+				res = append(res, m)
+			}
+			start = -1
+		}
+	}
+
+	return res
+}
+
+func parseIPMechanism(term string) (mechanism, bool) {
+	m := mechanism{}
+	if len(term) > 0 && strings.ContainsRune("+-~?", rune(term[0])) {
+		m.qualifier = term[:1]
+		term = term[1:]
+	}
+
+	if len(term) < 4 || term[3] != ':' {
+		return m, false
+	}
+
+	bits := 0
+	switch strings.ToLower(term[:3]) {
+	case "ip4":
+		bits = 32
+	case "ip6":
+		bits = 128
+	default:
+		return m, false
+	}
+
+	addr := term[4:]
+	if !strings.Contains(addr, "/") {
+		addr += "/" + strconv.Itoa(bits)
+	}
+
+	ip, ipNet, err := net.ParseCIDR(addr)
+	if err != nil || (bits == 32) != (ip.To4() != nil) {
+		return m, false
+	}
+
+	m.ipNet = ipNet
+	return m, true
+}
+
+// within checks if all addresses of the mechanism are part of the network
+func (m mechanism) within(ipNet *net.IPNet) bool {
+	mOnes, mBits := m.ipNet.Mask.Size()
+	ones, bits := ipNet.Mask.Size()
+	if mBits != bits {
+		return false
+	}
+
+	return ones <= mOnes && ipNet.Contains(m.ipNet.IP)
+}
+
+// IsPolicy checks if the value is a SPF policy
+func IsPolicy(value string) bool {
+	_, ok := parse(value)
+	return ok
+}
+
+// Matches checks if the value is a SPF policy with an ip4 or ip6 mechanism in the network
+func Matches(value string, ipNet *net.IPNet) bool {
+	rec, ok := parse(value)
+	if !ok {
+		return false
+	}
+
+	for _, m := range rec.ipMechanisms() {
+		if m.within(ipNet) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Drain removes the ip4 and ip6 mechanisms in the network from the SPF policy. If newIP is set, the first mechanism is replaced by a mechanism for newIP.
+func Drain(value string, ipNet *net.IPNet, newIP net.IP) string {
+	rec, ok := parse(value)
+	if !ok {
+		return value
+	}
+
+	text := rec.text()
+	edits := make([]edit, 0)
+	replaced := newIP == nil || containsMechanism(rec.ipMechanisms(), newIP)
+	for _, m := range rec.ipMechanisms() {
+		if !m.within(ipNet) {
+			continue
+		}
+
+		if !replaced {
+			edits = append(edits, edit{start: m.start, end: m.end, text: m.qualifier + ipMechanism(newIP)})
+			replaced = true
+			continue
+		}
+
+		start := m.start
+		if start > 0 && text[start-1] == ' ' {
+			start--
+		}
+		edits = append(edits, edit{start: start, end: m.end})
+	}
+
+	return rec.apply(edits).String()
+}
+
+// RemovesAllMechanisms checks if the policy before has ip4 or ip6 mechanisms and after has none
+func RemovesAllMechanisms(before, after string) bool {
+	b, ok := parse(before)
+	if !ok || len(b.ipMechanisms()) == 0 {
+		return false
+	}
+
+	a, ok := parse(after)
+	return !ok || len(a.ipMechanisms()) == 0
+}
+
+func containsMechanism(mechanisms []mechanism, ip net.IP) bool {
+	for _, m := range mechanisms {
+		ones, bits := m.ipNet.Mask.Size()
+		if ones == bits && m.ipNet.IP.Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ipMechanism(ip net.IP) string {
+	if ip.To4() != nil {
+		return "ip4:" + ip.String()
+	}
+
+	return "ip6:" + ip.String()
+}
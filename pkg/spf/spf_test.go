@@ -0,0 +1,253 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package spf
+
+import (
+	"net"
+	"slices"
+	"testing"
+)
+
+func mustParseCIDR(t *testing.T, s string) *net.IPNet {
+	t.Helper()
+
+	_, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ipNet
+}
+
+func TestParseCharacterStrings(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+		ok    bool
+	}{
+		{
+			name:  "single string",
+			value: `"v=spf1 -all"`,
+			want:  []string{"v=spf1 -all"},
+			ok:    true,
+		},
+		{
+			name:  "multiple strings",
+			value: `"v=spf1 ip4:10.0.0" ".1 -all"`,
+			want:  []string{"v=spf1 ip4:10.0.0", ".1 -all"},
+			ok:    true,
+		},
+		{
+			name:  "escaped characters",
+			value: `"a\"b\\c\059d"`,
+			want:  []string{`a"b\c;d`},
+			ok:    true,
+		},
+		{
+			name:  "unterminated",
+			value: `"v=spf1 -all`,
+			ok:    false,
+		},
+		{
+			name:  "unquoted text between strings",
+			value: `"a" b "c"`,
+			ok:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := parseCharacterStrings(test.value)
+			if ok != test.ok {
+				t.Fatalf("expected ok=%v, got %v", test.ok, ok)
+			}
+
+			if ok && !slices.Equal(got, test.want) {
+				t.Fatalf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		net   string
+		want  bool
+	}{
+		{
+			name:  "ip4 in network",
+			value: `"v=spf1 ip4:10.0.0.5 -all"`,
+			net:   "10.0.0.0/24",
+			want:  true,
+		},
+		{
+			name:  "ip4 network in network",
+			value: `"v=spf1 ip4:10.0.0.0/28 -all"`,
+			net:   "10.0.0.0/24",
+			want:  true,
+		},
+		{
+			name:  "ip4 network larger than network",
+			value: `"v=spf1 ip4:10.0.0.0/16 -all"`,
+			net:   "10.0.0.0/24",
+			want:  false,
+		},
+		{
+			name:  "ip6 with qualifier",
+			value: `"v=spf1 ~ip6:2001:db8::1 -all"`,
+			net:   "2001:db8::/64",
+			want:  true,
+		},
+		{
+			name:  "mechanism split across strings",
+			value: `"v=spf1 ip4:10.0" ".0.5 -all"`,
+			net:   "10.0.0.5/32",
+			want:  true,
+		},
+		{
+			name:  "unquoted value",
+			value: `v=spf1 ip4:10.0.0.5 -all`,
+			net:   "10.0.0.5/32",
+			want:  true,
+		},
+		{
+			name:  "no SPF policy",
+			value: `"ip4:10.0.0.5"`,
+			net:   "10.0.0.0/24",
+			want:  false,
+		},
+		{
+			name:  "other version",
+			value: `"v=spf10 ip4:10.0.0.5"`,
+			net:   "10.0.0.0/24",
+			want:  false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Matches(test.value, mustParseCIDR(t, test.net))
+			if got != test.want {
+				t.Fatalf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestDrain(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		net   string
+		newIP string
+		want  string
+	}{
+		{
+			name:  "remove mechanism",
+			value: `"v=spf1 ip4:192.0.2.1 ip4:10.0.0.5 -all"`,
+			net:   "10.0.0.0/24",
+			want:  `"v=spf1 ip4:192.0.2.1 -all"`,
+		},
+		{
+			name:  "remove all mechanisms in network",
+			value: `"v=spf1 ip4:10.0.0.1 mx ip4:10.0.0.2 -all"`,
+			net:   "10.0.0.0/24",
+			want:  `"v=spf1 mx -all"`,
+		},
+		{
+			name:  "replace mechanism keeping qualifier",
+			value: `"v=spf1 ~ip4:10.0.0.5 -all"`,
+			net:   "10.0.0.5/32",
+			newIP: "10.1.0.1",
+			want:  `"v=spf1 ~ip4:10.1.0.1 -all"`,
+		},
+		{
+			name:  "replace ip4 by ip6",
+			value: `"v=spf1 ip4:10.0.0.5 -all"`,
+			net:   "10.0.0.5/32",
+			newIP: "2001:db8::1",
+			want:  `"v=spf1 ip6:2001:db8::1 -all"`,
+		},
+		{
+			name:  "replacement already present",
+			value: `"v=spf1 ip4:10.0.0.5 ip4:10.1.0.1 -all"`,
+			net:   "10.0.0.5/32",
+			newIP: "10.1.0.1",
+			want:  `"v=spf1 ip4:10.1.0.1 -all"`,
+		},
+		{
+			name:  "string splitting is kept",
+			value: `"v=spf1 ip4:192.0.2.1 ip4:10.0.0" ".0/28 ip6:2001:db8::1 -all"`,
+			net:   "10.0.0.0/24",
+			want:  `"v=spf1 ip4:192.0.2.1" " ip6:2001:db8::1 -all"`,
+		},
+		{
+			name:  "unquoted value",
+			value: `v=spf1 ip4:10.0.0.5 ip4:192.0.2.1 -all`,
+			net:   "10.0.0.5/32",
+			want:  `v=spf1 ip4:192.0.2.1 -all`,
+		},
+		{
+			name:  "no SPF policy",
+			value: `"google-site-verification=abc"`,
+			net:   "10.0.0.0/24",
+			want:  `"google-site-verification=abc"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var newIP net.IP
+			if len(test.newIP) > 0 {
+				newIP = net.ParseIP(test.newIP)
+			}
+
+			got := Drain(test.value, mustParseCIDR(t, test.net), newIP)
+			if got != test.want {
+				t.Fatalf("expected %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestRemovesAllMechanisms(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   bool
+	}{
+		{
+			name:   "last mechanism removed",
+			before: `"v=spf1 ip4:10.0.0.5 -all"`,
+			after:  `"v=spf1 -all"`,
+			want:   true,
+		},
+		{
+			name:   "mechanism left",
+			before: `"v=spf1 ip4:10.0.0.5 ip4:192.0.2.1 -all"`,
+			after:  `"v=spf1 ip4:192.0.2.1 -all"`,
+			want:   false,
+		},
+		{
+			name:   "policy without mechanisms",
+			before: `"v=spf1 mx -all"`,
+			after:  `"v=spf1 -all"`,
+			want:   false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := RemovesAllMechanisms(test.before, test.after)
+			if got != test.want {
+				t.Fatalf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
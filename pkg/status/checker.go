@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+
+	"github.com/czerwonk/dns-drain/pkg/changelog"
+	"github.com/czerwonk/dns-drain/pkg/provider"
+	"github.com/czerwonk/dns-drain/pkg/worker"
+)
+
+// Checker compares the changes of a changelog with the live record sets
+type Checker struct {
+	provider    provider.Provider
+	parallelism int
+}
+
+// NewChecker returns a checker reading up to parallelism zones concurrently (< 1 = unlimited)
+func NewChecker(p provider.Provider, parallelism int) *Checker {
+	return &Checker{
+		provider:    p,
+		parallelism: parallelism,
+	}
+}
+
+type recordKey struct {
+	record     string
+	recordType string
+}
+
+// Check determines the state of every change. The changes are reported in the order of the changelog.
+func (c *Checker) Check(ctx context.Context, changes *changelog.DnsChangeSet) (*Report, error) {
+	mutex := sync.Mutex{}
+	states := make(map[string]*zoneState)
+
+	pool := worker.NewPool(c.parallelism)
+	for z := range changes.GroupByZone() {
+		pool.Go(func() {
+			s := c.readZone(ctx, z)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			states[z] = s
+		})
+	}
+	pool.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("status check was interrupted: %w", err)
+	}
+
+	records := groupByRecord(changes.Changes)
+	report := newReport()
+	for _, x := range changes.Changes {
+		s := states[x.Zone]
+		if s.err != nil {
+			report.add(x, Unknown, nil, s.err.Error())
+			continue
+		}
+
+		live := s.values(x.Record, x.RecordType)
+		state, reason := changeState(x, records[x.Zone][recordKey{x.Record, x.RecordType}], live)
+		report.add(x, state, live, reason)
+	}
+
+	return report, nil
+}
+
+// zoneState contains the live record sets of a zone
+type zoneState struct {
+	records map[recordKey][]string
+	err     error
+}
+
+func (c *Checker) readZone(ctx context.Context, zone string) *zoneState {
+	recs, err := c.provider.ListRecordSets(ctx, zone)
+	if err != nil {
+		log.Printf("ERROR - %s: %s\n", zone, err)
+		return &zoneState{err: err}
+	}
+
+	s := &zoneState{records: make(map[recordKey][]string)}
+	for _, r := range recs {
+		s.records[recordKey{r.Name, r.Type}] = r.Values
+	}
+
+	return s
+}
+
+func (s *zoneState) values(record, recordType string) []string {
+	values, found := s.records[recordKey{record, recordType}]
+	if !found {
+		return make([]string, 0)
+	}
+
+	return values
+}
+
+func groupByRecord(changes []changelog.DnsChange) map[string]map[recordKey][]changelog.DnsChange {
+	m := make(map[string]map[recordKey][]changelog.DnsChange)
+	for _, c := range changes {
+		if m[c.Zone] == nil {
+			m[c.Zone] = make(map[recordKey][]changelog.DnsChange)
+		}
+
+		k := recordKey{c.Record, c.RecordType}
+		m[c.Zone][k] = append(m[c.Zone][k], c)
+	}
+
+	return m
+}
+
+// changeState determines the state of the change c. recordChanges are all changes of the record set in the changelog.
+func changeState(c changelog.DnsChange, recordChanges []changelog.DnsChange, live []string) (State, string) {
+	if diff := unexplainedValues(recordChanges, live); len(diff) > 0 {
+		return Drifted, fmt.Sprintf("values changed outside of the changelog: %v", diff)
+	}
+
+	present := slices.Contains(live, c.Value)
+	if present == (c.Action == changelog.Add) {
+		return Applied, ""
+	}
+
+	return Reverted, ""
+}
+
+// unexplainedValues returns the values differing between the live record set and the state after the last change,
+// which are not part of any change of the record set. Changelogs without record set state can not be checked for drift.
+func unexplainedValues(recordChanges []changelog.DnsChange, live []string) []string {
+	last := recordChanges[len(recordChanges)-1]
+	if last.Before == nil {
+		return nil
+	}
+
+	touched := make(map[string]bool)
+	for _, c := range recordChanges {
+		touched[c.Value] = true
+	}
+
+	diff := make([]string, 0)
+	for _, v := range live {
+		if !slices.Contains(last.After, v) && !touched[v] {
+			diff = append(diff, v)
+		}
+	}
+
+	for _, v := range last.After {
+		if !slices.Contains(live, v) && !touched[v] {
+			diff = append(diff, v)
+		}
+	}
+
+	return diff
+}
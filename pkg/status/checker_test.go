@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package status
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/czerwonk/dns-drain/pkg/changelog"
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+// fakeProvider returns fixed record sets per zone. Zones not found can not be read.
+type fakeProvider struct {
+	zones map[string][]*provider.RecordSet
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *fakeProvider) ListZones(context.Context) ([]string, error) {
+	zones := make([]string, 0, len(p.zones))
+	for z := range p.zones {
+		zones = append(zones, z)
+	}
+
+	return zones, nil
+}
+
+func (p *fakeProvider) ListRecordSets(_ context.Context, zone string) ([]*provider.RecordSet, error) {
+	recs, found := p.zones[zone]
+	if !found {
+		return nil, errors.New("zone not readable")
+	}
+
+	return recs, nil
+}
+
+func (p *fakeProvider) ApplyChange(context.Context, string, *provider.Change) error {
+	return errors.New("read only")
+}
+
+func change(zone, record, action, value string, before, after []string) changelog.DnsChange {
+	return changelog.DnsChange{
+		Action:     action,
+		Zone:       zone,
+		Record:     record,
+		RecordType: "A",
+		Value:      value,
+		Before:     before,
+		After:      after,
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p := &fakeProvider{
+		zones: map[string][]*provider.RecordSet{
+			"example.com.": {
+				{Name: "drained.example.com.", Type: "A", Values: []string{"10.0.0.2"}},
+				{Name: "reverted.example.com.", Type: "A", Values: []string{"10.0.0.1", "10.0.0.2"}},
+				{Name: "drifted.example.com.", Type: "A", Values: []string{"10.0.0.2", "10.0.0.9"}},
+				{Name: "replaced.example.com.", Type: "A", Values: []string{"10.0.0.2", "10.0.0.3"}},
+				{Name: "legacy.example.com.", Type: "A", Values: []string{"10.0.0.2", "10.0.0.9"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		change changelog.DnsChange
+		want   State
+	}{
+		{
+			name:   "drained",
+			change: change("example.com.", "drained.example.com.", changelog.Remove, "10.0.0.1", []string{"10.0.0.1", "10.0.0.2"}, []string{"10.0.0.2"}),
+			want:   Applied,
+		},
+		{
+			name:   "reverted",
+			change: change("example.com.", "reverted.example.com.", changelog.Remove, "10.0.0.1", []string{"10.0.0.1", "10.0.0.2"}, []string{"10.0.0.2"}),
+			want:   Reverted,
+		},
+		{
+			name:   "drifted",
+			change: change("example.com.", "drifted.example.com.", changelog.Remove, "10.0.0.1", []string{"10.0.0.1", "10.0.0.2"}, []string{"10.0.0.2"}),
+			want:   Drifted,
+		},
+		{
+			name:   "replacement added",
+			change: change("example.com.", "replaced.example.com.", changelog.Add, "10.0.0.3", []string{"10.0.0.1", "10.0.0.2"}, []string{"10.0.0.2", "10.0.0.3"}),
+			want:   Applied,
+		},
+		{
+			name:   "record set removed",
+			change: change("example.com.", "removed.example.com.", changelog.Remove, "10.0.0.1", []string{"10.0.0.1"}, []string{}),
+			want:   Applied,
+		},
+		{
+			name:   "changelog without state",
+			change: change("example.com.", "legacy.example.com.", changelog.Remove, "10.0.0.1", nil, nil),
+			want:   Applied,
+		},
+		{
+			name:   "zone not readable",
+			change: change("example.org.", "www.example.org.", changelog.Remove, "10.0.0.1", []string{"10.0.0.1", "10.0.0.2"}, []string{"10.0.0.2"}),
+			want:   Unknown,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := NewChecker(p, 1).Check(context.Background(), &changelog.DnsChangeSet{Changes: []changelog.DnsChange{test.change}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := report.Changes[0]
+			if got.State != test.want {
+				t.Fatalf("expected %s, got %s (%s)", test.want, got.State, got.Reason)
+			}
+
+			if report.Summary[test.want] != 1 {
+				t.Fatalf("unexpected summary %v", report.Summary)
+			}
+		})
+	}
+}
+
+func TestCheckOrder(t *testing.T) {
+	p := &fakeProvider{
+		zones: map[string][]*provider.RecordSet{
+			"example.com.": {{Name: "www.example.com.", Type: "A", Values: []string{"10.0.0.2", "10.0.0.3"}}},
+			"example.net.": {{Name: "www.example.net.", Type: "A", Values: []string{"10.0.0.2"}}},
+		},
+	}
+
+	changes := &changelog.DnsChangeSet{Changes: []changelog.DnsChange{
+		change("example.net.", "www.example.net.", changelog.Remove, "10.0.0.1", []string{"10.0.0.1", "10.0.0.2"}, []string{"10.0.0.2"}),
+		change("example.com.", "www.example.com.", changelog.Remove, "10.0.0.1", []string{"10.0.0.1", "10.0.0.2"}, []string{"10.0.0.2", "10.0.0.3"}),
+		change("example.com.", "www.example.com.", changelog.Add, "10.0.0.3", []string{"10.0.0.1", "10.0.0.2"}, []string{"10.0.0.2", "10.0.0.3"}),
+	}}
+
+	report, err := NewChecker(p, 0).Check(context.Background(), changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, c := range report.Changes {
+		if c.Zone != changes.Changes[i].Zone || c.Value != changes.Changes[i].Value || c.State != Applied {
+			t.Fatalf("unexpected status %d: %+v", i, c)
+		}
+	}
+}
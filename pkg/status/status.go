@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package status
+
+import "github.com/czerwonk/dns-drain/pkg/changelog"
+
+// State describes if a change of the changelog is still in effect
+type State string
+
+const (
+	// Applied means the change is still in effect
+	Applied State = "applied"
+
+	// Reverted means the change is no longer in effect
+	Reverted State = "reverted"
+
+	// Drifted means the record set was changed by someone else after the change
+	Drifted State = "drifted"
+
+	// Unknown means the state could not be determined (e.g. the zone could not be read)
+	Unknown State = "unknown"
+)
+
+// ChangeStatus is the state of a single change of the changelog
+type ChangeStatus struct {
+	Zone       string   `json:"zone"`
+	Record     string   `json:"record"`
+	RecordType string   `json:"recordType"`
+	Action     string   `json:"action"`
+	Value      string   `json:"value"`
+	State      State    `json:"state"`
+	Live       []string `json:"live"`
+	Reason     string   `json:"reason,omitempty"`
+}
+
+// Report contains the state of all changes of a changelog
+type Report struct {
+	Changes []*ChangeStatus `json:"changes"`
+	Summary map[State]int   `json:"summary"`
+}
+
+func newReport() *Report {
+	return &Report{
+		Changes: make([]*ChangeStatus, 0),
+		Summary: map[State]int{Applied: 0, Reverted: 0, Drifted: 0, Unknown: 0},
+	}
+}
+
+func (r *Report) add(c changelog.DnsChange, state State, live []string, reason string) {
+	r.Changes = append(r.Changes, &ChangeStatus{
+		Zone:       c.Zone,
+		Record:     c.Record,
+		RecordType: c.RecordType,
+		Action:     c.Action,
+		Value:      c.Value,
+		State:      state,
+		Live:       live,
+		Reason:     reason,
+	})
+	r.Summary[state]++
+}
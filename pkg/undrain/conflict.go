@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package undrain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/czerwonk/dns-drain/pkg/changelog"
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+// ConflictPolicy defines how record sets changed after the drain are handled
+type ConflictPolicy string
+
+const (
+	// ConflictSkip leaves the record set untouched and reports the conflict
+	ConflictSkip ConflictPolicy = "skip"
+
+	// ConflictMerge reverts the changes of the drain and keeps all other changes of the live record set
+	ConflictMerge ConflictPolicy = "merge"
+
+	// ConflictOverwrite restores the record set as it was before the drain
+	ConflictOverwrite ConflictPolicy = "overwrite"
+)
+
+// ErrConflict is reported for record sets changed after the drain
+var ErrConflict = errors.New("record set was changed after the drain")
+
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(s); p {
+	case ConflictSkip, ConflictMerge, ConflictOverwrite:
+		return p, nil
+	default:
+		return "", fmt.Errorf("invalid conflict policy %q (valid: skip, merge, overwrite)", s)
+	}
+}
+
+// alreadyReverted checks if the record set is in the state before the changes, e.g. if an undrain is repeated
+func alreadyReverted(changes []changelog.DnsChange, rec *provider.RecordSet) bool {
+	if changes[0].Before != nil && provider.SameValues(rec.Values, changes[0].Before) {
+		return true
+	}
+
+	return provider.SameValues(rec.Values, getNewValues(changes, rec))
+}
+
+// checkConflict compares the live record set (nil if not existing) with the state after the last change of the record set
+func checkConflict(last changelog.DnsChange, changes []changelog.DnsChange, rec *provider.RecordSet) error {
+	live := make([]string, 0)
+	if rec != nil {
+		live = rec.Values
+	}
+
+	if last.Before != nil {
+		if !provider.SameValues(live, last.After) {
+			return fmt.Errorf("%w: expected %v, found %v", ErrConflict, last.After, live)
+		}
+
+		return nil
+	}
+
+	// changelogs without record set state: the values of the drain must still be present or absent
+	for _, c := range changes {
+		present := slices.Contains(live, c.Value)
+		if c.Action == changelog.Add && !present {
+			return fmt.Errorf("%w: %s not found", ErrConflict, c.Value)
+		}
+
+		if c.Action == changelog.Remove && present {
+			return fmt.Errorf("%w: %s was added again", ErrConflict, c.Value)
+		}
+	}
+
+	return nil
+}
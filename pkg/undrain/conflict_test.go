@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package undrain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/czerwonk/dns-drain/pkg/changelog"
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+func drainChange(action, value string, before, after []string) changelog.DnsChange {
+	return changelog.DnsChange{
+		Action:     action,
+		Zone:       "example.com.",
+		Record:     "www.example.com.",
+		RecordType: "A",
+		Value:      value,
+		Before:     before,
+		After:      after,
+		RunID:      "run",
+	}
+}
+
+func liveRecord(values ...string) *provider.RecordSet {
+	return &provider.RecordSet{
+		Name:   "www.example.com.",
+		Type:   "A",
+		Values: values,
+	}
+}
+
+func TestCheckConflict(t *testing.T) {
+	removed := drainChange(changelog.Remove, "10.0.0.1", []string{"10.0.0.1", "10.0.0.2"}, []string{"10.0.0.2"})
+	legacy := drainChange(changelog.Remove, "10.0.0.1", nil, nil)
+	created := drainChange(changelog.Add, "10.0.0.9", []string{}, []string{"10.0.0.9"})
+
+	tests := []struct {
+		name     string
+		change   changelog.DnsChange
+		rec      *provider.RecordSet
+		conflict bool
+	}{
+		{
+			name:   "unchanged since drain",
+			change: removed,
+			rec:    liveRecord("10.0.0.2"),
+		},
+		{
+			name:   "order ignored",
+			change: drainChange(changelog.Remove, "10.0.0.1", []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, []string{"10.0.0.2", "10.0.0.3"}),
+			rec:    liveRecord("10.0.0.3", "10.0.0.2"),
+		},
+		{
+			name:     "value added after drain",
+			change:   removed,
+			rec:      liveRecord("10.0.0.2", "10.0.0.9"),
+			conflict: true,
+		},
+		{
+			name:     "record removed after drain",
+			change:   removed,
+			conflict: true,
+		},
+		{
+			name:   "record set created by the drain",
+			change: created,
+			rec:    liveRecord("10.0.0.9"),
+		},
+		{
+			name:     "record set created by the drain removed",
+			change:   created,
+			conflict: true,
+		},
+		{
+			name:   "changelog without state",
+			change: legacy,
+			rec:    liveRecord("10.0.0.2"),
+		},
+		{
+			name:     "changelog without state, value added again",
+			change:   legacy,
+			rec:      liveRecord("10.0.0.1", "10.0.0.2"),
+			conflict: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkConflict(test.change, []changelog.DnsChange{test.change}, test.rec)
+			if test.conflict != (err != nil) {
+				t.Fatalf("expected conflict=%v, got %v", test.conflict, err)
+			}
+
+			if err != nil && !errors.Is(err, ErrConflict) {
+				t.Fatalf("expected ErrConflict, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAlreadyReverted(t *testing.T) {
+	replaced := []changelog.DnsChange{
+		drainChange(changelog.Remove, "10.0.0.1", []string{"10.0.0.1", "10.0.0.2"}, []string{"10.0.0.2", "10.0.0.3"}),
+		drainChange(changelog.Add, "10.0.0.3", []string{"10.0.0.1", "10.0.0.2"}, []string{"10.0.0.2", "10.0.0.3"}),
+	}
+
+	created := []changelog.DnsChange{
+		drainChange(changelog.Add, "10.0.0.9", []string{}, []string{"10.0.0.9"}),
+	}
+
+	tests := []struct {
+		name    string
+		changes []changelog.DnsChange
+		rec     *provider.RecordSet
+		want    bool
+	}{
+		{
+			name:    "state before drain",
+			changes: replaced,
+			rec:     liveRecord("10.0.0.2", "10.0.0.1"),
+			want:    true,
+		},
+		{
+			name:    "state after drain",
+			changes: replaced,
+			rec:     liveRecord("10.0.0.2", "10.0.0.3"),
+			want:    false,
+		},
+		{
+			name:    "changes reverted, other value added",
+			changes: replaced,
+			rec:     liveRecord("10.0.0.1", "10.0.0.2", "10.0.0.9"),
+			want:    true,
+		},
+		{
+			name:    "partially reverted",
+			changes: replaced,
+			rec:     liveRecord("10.0.0.1", "10.0.0.2", "10.0.0.3"),
+			want:    false,
+		},
+		{
+			name:    "record set created by the drain removed",
+			changes: created,
+			rec:     liveRecord(),
+			want:    true,
+		},
+		{
+			name:    "record set created by the drain",
+			changes: created,
+			rec:     liveRecord("10.0.0.9"),
+			want:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := alreadyReverted(test.changes, test.rec)
+			if got != test.want {
+				t.Fatalf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
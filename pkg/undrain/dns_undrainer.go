@@ -0,0 +1,289 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package undrain
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/czerwonk/dns-drain/pkg/changelog"
+	"github.com/czerwonk/dns-drain/pkg/provider"
+	"github.com/czerwonk/dns-drain/pkg/result"
+	"github.com/czerwonk/dns-drain/pkg/worker"
+)
+
+// DnsUndrainer implements the undrain logic independent of the DNS backend
+type DnsUndrainer struct {
+	provider provider.Provider
+	opt      *Options
+	updater  *provider.Updater
+	writer   *changelog.RecordSetWriter
+	tracker  *revertTracker
+}
+
+type groupKey struct {
+	record     string
+	recordType string
+}
+
+// NewUndrainer returns an undrainer writing the reverted changes to logger, so the undrain can be reverted as well
+func NewUndrainer(p provider.Provider, logger changelog.ChangeLogger, opt *Options) *DnsUndrainer {
+	return &DnsUndrainer{
+		provider: p,
+		opt:      opt,
+		updater:  provider.NewUpdater(p, opt.DryRun, opt.Limit),
+		writer:   changelog.NewRecordSetWriter(p, logger),
+	}
+}
+
+// RunID returns the identifier written to all changes of the undrainer
+func (u *DnsUndrainer) RunID() string {
+	return u.writer.RunID()
+}
+
+func (u *DnsUndrainer) Undrain(ctx context.Context, changes *changelog.DnsChangeSet) (*result.Result, error) {
+	log.Printf("Starting undrain run %s\n", u.writer.RunID())
+	res := result.NewResult()
+	u.tracker = newRevertTracker(changes)
+
+	selected := &changelog.DnsChangeSet{Changes: u.filterChanges(changes.Changes)}
+	log.Printf("Reverting %d of %d changes\n", len(selected.Changes), len(changes.Changes))
+
+	pool := worker.NewPool(u.opt.Parallelism)
+	for z, c := range selected.GroupByZone() {
+		pool.Go(func() {
+			u.undrainZone(ctx, z, c, res)
+		})
+	}
+	pool.Wait()
+
+	if u.opt.Wait && !u.opt.DryRun && ctx.Err() == nil {
+		u.waitForChanges(ctx, res)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return res, fmt.Errorf("undrain was interrupted: %w", err)
+	}
+
+	return res, nil
+}
+
+func (u *DnsUndrainer) undrainZone(ctx context.Context, zone string, changes []changelog.DnsChange, res *result.Result) {
+	if u.opt.SkipFilter != nil && u.opt.SkipFilter.MatchString(zone) {
+		return
+	}
+
+	if u.opt.ZoneFilter != nil && !u.opt.ZoneFilter.MatchString(zone) {
+		return
+	}
+
+	recs, err := u.provider.ListRecordSets(ctx, zone)
+	if err != nil {
+		log.Printf("ERROR - %s: %s\n", zone, err)
+		res.AddZoneFailure(zone, err)
+		return
+	}
+
+	res.AddZone(len(recs))
+	log.Printf("%s: %d record sets\n", zone, len(recs))
+
+	for r, c := range groupChanges(changes) {
+		if ctx.Err() != nil {
+			return
+		}
+
+		done, err := u.revertChange(ctx, r.record, c, recs)
+		switch {
+		case errors.Is(err, provider.ErrLimitReached):
+			res.AddSkipped()
+		case err != nil && ctx.Err() != nil:
+			return
+		case err != nil:
+			log.Printf("ERROR - %s %s: %s\n", r.recordType, r.record, err)
+			res.AddRecordFailure(zone, r.record, r.recordType, err)
+		case done:
+			res.AddChanged()
+		}
+	}
+}
+
+// waitForChanges waits for the changes to complete. Changes not completed are reported as failures.
+func (u *DnsUndrainer) waitForChanges(ctx context.Context, res *result.Result) {
+	for _, c := range provider.WaitForChanges(ctx, u.provider, u.opt.WaitTimeout) {
+		log.Printf("ERROR - %s %s: %s\n", c.RecordType, c.Record, c.Err)
+		res.AddRecordFailure(c.Zone, c.Record, c.RecordType, c.Err)
+	}
+}
+
+// Outstanding returns the changes not reverted by the last undrain
+func (u *DnsUndrainer) Outstanding(changes *changelog.DnsChangeSet) *changelog.DnsChangeSet {
+	return u.tracker.outstanding(changes)
+}
+
+func groupChanges(changes []changelog.DnsChange) map[groupKey][]changelog.DnsChange {
+	m := make(map[groupKey][]changelog.DnsChange)
+	for _, x := range changes {
+		key := groupKey{
+			record:     x.Record,
+			recordType: x.RecordType,
+		}
+
+		var arr []changelog.DnsChange
+		var found bool
+		if arr, found = m[key]; !found {
+			arr = make([]changelog.DnsChange, 0)
+		}
+
+		m[key] = append(arr, x)
+	}
+
+	return m
+}
+
+func (u *DnsUndrainer) revertChange(ctx context.Context, record string, changes []changelog.DnsChange, records []*provider.RecordSet) (bool, error) {
+	rec := provider.FindRecordSet(record, changes[0].RecordType, records)
+	found := rec != nil
+	if !found {
+		log.Printf("WARNING - Record %s not found in zone %s\n", record, changes[0].Zone)
+		rec = &provider.RecordSet{
+			Name:          record,
+			Type:          changes[0].RecordType,
+			TTL:           changes[0].TTL,
+			RoutingPolicy: changes[0].RoutingPolicy,
+			Values:        make([]string, 0),
+		}
+	}
+
+	if alreadyReverted(changes, rec) {
+		log.Printf("%s %s: changes already reverted\n", rec.Type, rec.Name)
+		u.tracker.markReverted(changes, rec.Values)
+		return false, nil
+	}
+
+	values, err := u.resolveValues(changes, rec, found)
+	if err != nil {
+		return false, err
+	}
+
+	updated := rec.WithValues(values)
+	updated.Attributes = getNewAttributes(changes, rec)
+
+	done, err := u.updater.UpdateRecordSet(ctx, changes[0].Zone, rec, updated)
+	if err != nil || !done {
+		return false, err
+	}
+
+	u.tracker.markReverted(changes, updated.Values)
+	return true, u.writer.LogChanges(changes[0].Zone, rec, updated)
+}
+
+// resolveValues returns the values to restore. Conflicts are handled according to the conflict policy.
+func (u *DnsUndrainer) resolveValues(changes []changelog.DnsChange, rec *provider.RecordSet, found bool) ([]string, error) {
+	live := rec
+	if !found {
+		live = nil
+	}
+
+	err := checkConflict(u.tracker.lastChange(changes[0]), changes, live)
+	if err == nil {
+		return getNewValues(changes, rec), nil
+	}
+
+	switch u.opt.OnConflict {
+	case ConflictMerge:
+		log.Printf("WARN - %s %s: %s. Merging changes.\n", rec.Type, rec.Name, err)
+	case ConflictOverwrite:
+		if u.filtersValues() {
+			log.Printf("WARN - %s %s: %s. Can not restore state before the drain for a subset of values, merging changes.\n", rec.Type, rec.Name, err)
+			break
+		}
+
+		if changes[0].Before != nil {
+			log.Printf("WARN - %s %s: %s. Restoring state before the drain.\n", rec.Type, rec.Name, err)
+			return slices.Clone(changes[0].Before), nil
+		}
+
+		log.Printf("WARN - %s %s: %s. State before the drain unknown, merging changes.\n", rec.Type, rec.Name, err)
+	default:
+		return nil, err
+	}
+
+	return getNewValues(changes, rec), nil
+}
+
+func getNewValues(changes []changelog.DnsChange, record *provider.RecordSet) []string {
+	m := make(map[string]int)
+	for _, x := range record.Values {
+		m[x] = 1
+	}
+
+	for _, c := range changes {
+		if c.Action == changelog.Add {
+			delete(m, c.Value)
+		} else {
+			m[c.Value] = 1
+		}
+	}
+
+	r := make([]string, 0)
+	for k, v := range m {
+		if v == 1 {
+			r = append(r, k)
+		}
+	}
+
+	sortValues(r, record.Values, changes[0].Before)
+
+	return r
+}
+
+// sortValues restores the order of the values before the drain. Values unknown at that time are appended in the current order.
+func sortValues(values []string, current []string, before []string) {
+	rank := func(v string) (int, int) {
+		if i := slices.Index(before, v); i >= 0 {
+			return 0, i
+		}
+
+		if i := slices.Index(current, v); i >= 0 {
+			return 1, i
+		}
+
+		return 2, 0
+	}
+
+	slices.SortStableFunc(values, func(a, b string) int {
+		ga, ia := rank(a)
+		gb, ib := rank(b)
+		if ga != gb {
+			return cmp.Compare(ga, gb)
+		}
+
+		if ia != ib {
+			return cmp.Compare(ia, ib)
+		}
+
+		return strings.Compare(a, b)
+	})
+}
+
+func getNewAttributes(changes []changelog.DnsChange, record *provider.RecordSet) map[string]map[string]string {
+	m := make(map[string]map[string]string)
+	for k, v := range record.Attributes {
+		m[k] = v
+	}
+
+	for _, c := range changes {
+		if c.Action == changelog.Remove && len(c.Attributes) > 0 {
+			m[c.Value] = c.Attributes
+		}
+	}
+
+	return m
+}
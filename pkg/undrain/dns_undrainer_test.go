@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package undrain
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/czerwonk/dns-drain/pkg/changelog"
+	"github.com/czerwonk/dns-drain/pkg/provider"
+	"github.com/czerwonk/dns-drain/pkg/result"
+)
+
+// memoryProvider holds the record sets of a single zone in memory
+type memoryProvider struct {
+	mutex sync.Mutex
+	zone  string
+	recs  []*provider.RecordSet
+}
+
+func (p *memoryProvider) Name() string {
+	return "memory"
+}
+
+func (p *memoryProvider) ListZones(context.Context) ([]string, error) {
+	return []string{p.zone}, nil
+}
+
+func (p *memoryProvider) ListRecordSets(context.Context, string) ([]*provider.RecordSet, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	recs := make([]*provider.RecordSet, 0, len(p.recs))
+	for _, r := range p.recs {
+		recs = append(recs, r.WithValues(slices.Clone(r.Values)))
+	}
+
+	return recs, nil
+}
+
+func (p *memoryProvider) ApplyChange(_ context.Context, _ string, change *provider.Change) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	rec := change.After
+	if rec == nil {
+		rec = change.Before
+	}
+
+	p.recs = slices.DeleteFunc(p.recs, func(r *provider.RecordSet) bool {
+		return r.Name == rec.Name && r.Type == rec.Type
+	})
+
+	if change.After != nil {
+		p.recs = append(p.recs, change.After)
+	}
+
+	return nil
+}
+
+type discardLogger struct{}
+
+func (discardLogger) LogChange(changelog.DnsChange) error {
+	return nil
+}
+
+func TestUndrainRepeatedAfterLimit(t *testing.T) {
+	p := &memoryProvider{
+		zone: "example.com.",
+		recs: []*provider.RecordSet{
+			{Name: "a.example.com.", Type: "A", TTL: 300, Values: []string{"10.0.0.2"}},
+			{Name: "b.example.com.", Type: "A", TTL: 300, Values: []string{"10.0.0.3"}},
+		},
+	}
+
+	changes := &changelog.DnsChangeSet{Changes: []changelog.DnsChange{
+		{Action: changelog.Remove, Zone: "example.com.", Record: "a.example.com.", RecordType: "A", Value: "10.0.0.1",
+			TTL: 300, Before: []string{"10.0.0.1", "10.0.0.2"}, After: []string{"10.0.0.2"}},
+		{Action: changelog.Remove, Zone: "example.com.", Record: "b.example.com.", RecordType: "A", Value: "10.0.0.1",
+			TTL: 300, Before: []string{"10.0.0.1", "10.0.0.3"}, After: []string{"10.0.0.3"}},
+	}}
+
+	limited := NewUndrainer(p, discardLogger{}, &Options{Limit: 1, OnConflict: ConflictSkip})
+	res, err := limited.Undrain(context.Background(), changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.RecordsChanged != 1 || res.Status() != result.Success {
+		t.Fatalf("unexpected result of limited undrain: %s", res)
+	}
+
+	if n := len(limited.Outstanding(changes).Changes); n != 1 {
+		t.Fatalf("expected 1 outstanding change, got %d", n)
+	}
+
+	full := NewUndrainer(p, discardLogger{}, &Options{Limit: -1, OnConflict: ConflictSkip})
+	res, err = full.Undrain(context.Background(), changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.RecordsChanged != 1 || res.Status() != result.Success {
+		t.Fatalf("unexpected result of repeated undrain: %s", res)
+	}
+
+	for _, r := range p.recs {
+		if !slices.Contains(r.Values, "10.0.0.1") {
+			t.Fatalf("%s: 10.0.0.1 not restored: %v", r.Name, r.Values)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package undrain
+
+import (
+	"net"
+
+	"github.com/czerwonk/dns-drain/pkg/changelog"
+	"github.com/czerwonk/dns-drain/pkg/spf"
+)
+
+// filterChanges returns the changes matching the name, type and value filters.
+// If a SPF policy is selected by the network filter, the policy added by the same run is selected as well, so it is replaced again.
+func (u *DnsUndrainer) filterChanges(changes []changelog.DnsChange) []changelog.DnsChange {
+	policies := make(map[runKey]bool)
+	for _, c := range changes {
+		if u.opt.NetFilter != nil && spf.IsRecordType(c.RecordType) && c.Action == changelog.Remove && u.matches(c) {
+			policies[keyOfRun(c)] = true
+		}
+	}
+
+	res := make([]changelog.DnsChange, 0)
+	for _, c := range changes {
+		if u.matches(c) || (c.Action == changelog.Add && policies[keyOfRun(c)] && spf.IsPolicy(c.Value)) {
+			res = append(res, c)
+		}
+	}
+
+	return res
+}
+
+type runKey struct {
+	recordKey
+	runID string
+}
+
+func keyOfRun(c changelog.DnsChange) runKey {
+	return runKey{recordKey: keyOfRecord(c), runID: c.RunID}
+}
+
+func (u *DnsUndrainer) matches(c changelog.DnsChange) bool {
+	if u.opt.NameFilter != nil && !u.opt.NameFilter.MatchString(c.Record) {
+		return false
+	}
+
+	if len(u.opt.TypeFilter) > 0 && u.opt.TypeFilter != c.RecordType {
+		return false
+	}
+
+	if len(u.opt.ValueFilter) > 0 && u.opt.ValueFilter != c.Value {
+		return false
+	}
+
+	return u.opt.NetFilter == nil || isInNet(c, u.opt.NetFilter)
+}
+
+// filtersValues returns true if only changes of some values are reverted
+func (u *DnsUndrainer) filtersValues() bool {
+	return len(u.opt.ValueFilter) > 0 || u.opt.NetFilter != nil
+}
+
+func isInNet(c changelog.DnsChange, ipNet *net.IPNet) bool {
+	if spf.IsRecordType(c.RecordType) {
+		return spf.Matches(c.Value, ipNet)
+	}
+
+	ip := net.ParseIP(c.Value)
+	return ip != nil && ipNet.Contains(ip)
+}
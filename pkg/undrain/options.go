@@ -4,11 +4,29 @@
 
 package undrain
 
-import "regexp"
+import (
+	"net"
+	"regexp"
+	"time"
+)
 
 type Options struct {
-	DryRun     bool
-	ZoneFilter *regexp.Regexp
-	SkipFilter *regexp.Regexp
-	Limit      int64
+	DryRun      bool
+	ZoneFilter  *regexp.Regexp
+	SkipFilter  *regexp.Regexp
+	NameFilter  *regexp.Regexp
+	TypeFilter  string
+	ValueFilter string
+	NetFilter   *net.IPNet
+	Limit       int64
+	Wait        bool
+	WaitTimeout time.Duration
+	Parallelism int
+	OnConflict  ConflictPolicy
+}
+
+// SelectsSubset returns true if the filters limit the undrain to a subset of the changes
+func (o *Options) SelectsSubset() bool {
+	return o.ZoneFilter != nil || o.SkipFilter != nil || o.NameFilter != nil || len(o.TypeFilter) > 0 ||
+		len(o.ValueFilter) > 0 || o.NetFilter != nil || o.Limit >= 0
 }
@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package undrain
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/czerwonk/dns-drain/pkg/changelog"
+)
+
+// revertTracker keeps track of the changes reverted by an undrain
+type revertTracker struct {
+	mutex    sync.Mutex
+	reverted map[changeKey]bool
+	state    map[recordKey][]string
+	last     map[recordKey]changelog.DnsChange
+}
+
+type changeKey struct {
+	recordKey
+	value  string
+	action string
+	runID  string
+}
+
+type recordKey struct {
+	zone       string
+	record     string
+	recordType string
+}
+
+func newRevertTracker(changes *changelog.DnsChangeSet) *revertTracker {
+	t := &revertTracker{
+		reverted: make(map[changeKey]bool),
+		state:    make(map[recordKey][]string),
+		last:     make(map[recordKey]changelog.DnsChange),
+	}
+
+	for _, c := range changes.Changes {
+		t.last[keyOfRecord(c)] = c
+	}
+
+	return t
+}
+
+// lastChange returns the most recent change of the record set in the changelog, including changes not selected by the filters
+func (t *revertTracker) lastChange(c changelog.DnsChange) changelog.DnsChange {
+	return t.last[keyOfRecord(c)]
+}
+
+func keyOfRecord(c changelog.DnsChange) recordKey {
+	return recordKey{zone: c.Zone, record: c.Record, recordType: c.RecordType}
+}
+
+func keyOfChange(c changelog.DnsChange) changeKey {
+	return changeKey{recordKey: keyOfRecord(c), value: c.Value, action: c.Action, runID: c.RunID}
+}
+
+// markReverted records the changes as reverted and the values of the record set after the undrain
+func (t *revertTracker) markReverted(changes []changelog.DnsChange, values []string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, c := range changes {
+		t.reverted[keyOfChange(c)] = true
+	}
+
+	t.state[keyOfRecord(changes[0])] = values
+}
+
+// outstanding returns the changes not reverted. For record sets partially reverted the state before and after the changes is updated,
+// so a later undrain neither detects a conflict nor drops the values already restored.
+func (t *revertTracker) outstanding(changes *changelog.DnsChangeSet) *changelog.DnsChangeSet {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	res := &changelog.DnsChangeSet{Changes: make([]changelog.DnsChange, 0)}
+	for _, c := range changes.Changes {
+		if !t.reverted[keyOfChange(c)] {
+			res.Changes = append(res.Changes, c)
+		}
+	}
+
+	for k, changes := range groupByRecord(res.Changes) {
+		values, found := t.state[k]
+		if !found {
+			continue
+		}
+
+		before := revertedValues(values, changes)
+		for _, c := range changes {
+			if c.Before != nil {
+				c.Before = before
+				c.After = append(make([]string, 0, len(values)), values...)
+			}
+		}
+	}
+
+	return res
+}
+
+// groupByRecord returns pointers to the changes per record set
+func groupByRecord(changes []changelog.DnsChange) map[recordKey][]*changelog.DnsChange {
+	m := make(map[recordKey][]*changelog.DnsChange)
+	for i := range changes {
+		k := keyOfRecord(changes[i])
+		m[k] = append(m[k], &changes[i])
+	}
+
+	return m
+}
+
+// revertedValues returns the values after reverting the changes
+func revertedValues(values []string, changes []*changelog.DnsChange) []string {
+	res := append(make([]string, 0, len(values)), values...)
+	for _, c := range changes {
+		if c.Action == changelog.Add {
+			res = slices.DeleteFunc(res, func(v string) bool { return v == c.Value })
+		} else if !slices.Contains(res, c.Value) {
+			res = append(res, c.Value)
+		}
+	}
+
+	return res
+}
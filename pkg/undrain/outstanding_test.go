@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package undrain
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/czerwonk/dns-drain/pkg/changelog"
+)
+
+func TestOutstanding(t *testing.T) {
+	before := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}
+	after := []string{"10.0.0.3"}
+	other := changelog.DnsChange{Action: changelog.Remove, Zone: "example.com.", Record: "api.example.com.", RecordType: "A",
+		Value: "10.0.0.1", Before: []string{"10.0.0.1", "10.0.0.4"}, After: []string{"10.0.0.4"}, RunID: "run"}
+
+	changes := &changelog.DnsChangeSet{Changes: []changelog.DnsChange{
+		drainChange(changelog.Remove, "10.0.0.1", before, after),
+		drainChange(changelog.Remove, "10.0.0.2", before, after),
+		other,
+	}}
+
+	tracker := newRevertTracker(changes)
+	tracker.markReverted(changes.Changes[:1], []string{"10.0.0.1", "10.0.0.3"})
+
+	res := tracker.outstanding(changes)
+	if len(res.Changes) != 2 {
+		t.Fatalf("expected 2 outstanding changes, got %d", len(res.Changes))
+	}
+
+	partial := res.Changes[0]
+	if partial.Value != "10.0.0.2" {
+		t.Fatalf("unexpected outstanding change %s", partial.Value)
+	}
+
+	if !slices.Equal(partial.After, []string{"10.0.0.1", "10.0.0.3"}) {
+		t.Fatalf("expected state after the changes to be updated, got %v", partial.After)
+	}
+
+	if !slices.Equal(partial.Before, []string{"10.0.0.1", "10.0.0.3", "10.0.0.2"}) {
+		t.Fatalf("expected state before the changes to be updated, got %v", partial.Before)
+	}
+
+	untouched := res.Changes[1]
+	if !slices.Equal(untouched.Before, other.Before) || !slices.Equal(untouched.After, other.After) {
+		t.Fatalf("expected state of record not reverted to be kept, got %v/%v", untouched.Before, untouched.After)
+	}
+
+	if err := checkConflict(newRevertTracker(res).lastChange(partial), res.Changes[:1], liveRecord("10.0.0.3", "10.0.0.1")); err != nil {
+		t.Fatalf("expected no conflict for the rewritten changelog, got %v", err)
+	}
+}
+
+func TestOutstandingAllReverted(t *testing.T) {
+	changes := &changelog.DnsChangeSet{Changes: []changelog.DnsChange{
+		drainChange(changelog.Remove, "10.0.0.1", []string{"10.0.0.1", "10.0.0.2"}, []string{"10.0.0.2"}),
+	}}
+
+	tracker := newRevertTracker(changes)
+	tracker.markReverted(changes.Changes, []string{"10.0.0.1", "10.0.0.2"})
+
+	res := tracker.outstanding(changes)
+	if len(res.Changes) != 0 {
+		t.Fatalf("expected no outstanding changes, got %d", len(res.Changes))
+	}
+}
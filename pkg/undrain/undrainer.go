@@ -4,8 +4,13 @@
 
 package undrain
 
-import "github.com/czerwonk/dns-drain/pkg/changelog"
+import (
+	"context"
+
+	"github.com/czerwonk/dns-drain/pkg/changelog"
+	"github.com/czerwonk/dns-drain/pkg/result"
+)
 
 type Undrainer interface {
-	Undrain(changes *changelog.DnsChangeSet) error
+	Undrain(ctx context.Context, changes *changelog.DnsChangeSet) (*result.Result, error)
 }
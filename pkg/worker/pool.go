@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package worker
+
+import "sync"
+
+// Pool runs functions in goroutines limiting the number running at the same time
+type Pool struct {
+	wg  sync.WaitGroup
+	sem chan struct{}
+}
+
+// NewPool returns a pool running up to size functions concurrently (size < 1 = unlimited)
+func NewPool(size int) *Pool {
+	p := &Pool{}
+	if size > 0 {
+		p.sem = make(chan struct{}, size)
+	}
+
+	return p
+}
+
+// Go runs f in a new goroutine. It blocks until a worker is available.
+func (p *Pool) Go(f func()) {
+	if p.sem == nil {
+		p.wg.Go(f)
+		return
+	}
+
+	p.sem <- struct{}{}
+	p.wg.Go(func() {
+		defer func() { <-p.sem }()
+		f()
+	})
+}
+
+// Wait blocks until all functions have returned
+func (p *Pool) Wait() {
+	p.wg.Wait()
+}
@@ -0,0 +1,13 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package zonefile
+
+// DefaultPattern selects the zone files in the directory if no pattern is configured
+const DefaultPattern = "*.zone"
+
+type Config struct {
+	Dir     string
+	Pattern string
+}
@@ -0,0 +1,245 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package zonefile
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+const providerName = "zonefile"
+
+// ZoneFileProvider modifies BIND master files in place. The serial of the SOA record is incremented once per zone and run.
+type ZoneFileProvider struct {
+	cfg    Config
+	mutex  sync.Mutex
+	files  map[string]string
+	bumped map[string]bool
+}
+
+func NewProvider(cfg Config) *ZoneFileProvider {
+	return &ZoneFileProvider{
+		cfg:    cfg,
+		files:  make(map[string]string),
+		bumped: make(map[string]bool),
+	}
+}
+
+func (p *ZoneFileProvider) Name() string {
+	return providerName
+}
+
+func (p *ZoneFileProvider) ListZones(ctx context.Context) ([]string, error) {
+	pattern := p.cfg.Pattern
+	if pattern == "" {
+		pattern = DefaultPattern
+	}
+
+	paths, err := filepath.Glob(filepath.Join(p.cfg.Dir, pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	zones := make([]string, 0, len(paths))
+	for _, path := range paths {
+		z, err := loadZoneFile(path)
+		if err != nil {
+			log.Printf("WARN - Skipping %s: %s\n", path, err)
+			continue
+		}
+
+		name, err := z.name()
+		if err != nil {
+			log.Printf("WARN - Skipping %s: %s\n", path, err)
+			continue
+		}
+
+		if other, found := p.files[name]; found && other != path {
+			return nil, fmt.Errorf("zone %s is defined in %s and %s", name, other, path)
+		}
+
+		p.files[name] = path
+		zones = append(zones, name)
+	}
+
+	return zones, nil
+}
+
+func (p *ZoneFileProvider) ListRecordSets(ctx context.Context, zone string) ([]*provider.RecordSet, error) {
+	path, err := p.zonePath(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	z, err := loadZoneFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	recs := make([]*provider.RecordSet, 0)
+	index := make(map[recordKey]*provider.RecordSet)
+	for _, e := range z.entries {
+		h := e.rr.Header()
+		if h.Rrtype == dns.TypeSOA {
+			continue
+		}
+
+		key := recordKey{name: h.Name, rrType: h.Rrtype}
+		rec, found := index[key]
+		if !found {
+			rec = &provider.RecordSet{
+				Name:   h.Name,
+				Type:   dns.TypeToString[h.Rrtype],
+				TTL:    int64(h.Ttl),
+				Values: make([]string, 0),
+			}
+			index[key] = rec
+			recs = append(recs, rec)
+		}
+
+		rec.Values = append(rec.Values, rdata(e.rr))
+	}
+
+	return recs, nil
+}
+
+func (p *ZoneFileProvider) ApplyChange(ctx context.Context, zone string, change *provider.Change) error {
+	_, err := p.ApplyChanges(ctx, zone, []*provider.Change{change})
+	return err
+}
+
+// ApplyChanges writes all changes of the zone at once. The file is left untouched if one of the changes can not be applied.
+func (p *ZoneFileProvider) ApplyChanges(ctx context.Context, zone string, changes []*provider.Change) (int, error) {
+	path, err := p.zonePath(ctx, zone)
+	if err != nil {
+		return 0, err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	z, err := loadZoneFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, c := range changes {
+		err = applyChange(z, c)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if !p.bumped[zone] {
+		err = z.bumpSerial(time.Now())
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = z.save()
+	if err != nil {
+		return 0, err
+	}
+
+	p.bumped[zone] = true
+	return len(changes), nil
+}
+
+func applyChange(z *zoneFile, change *provider.Change) error {
+	removed, added := diffChange(change)
+
+	// values replaced by others are edited in place to keep the position and formatting of the entry
+	for len(removed) > 0 && len(added) > 0 {
+		err := z.replaceRecord(change.After.Name, change.After.Type, change.After.TTL, removed[0], added[0])
+		if err != nil {
+			return err
+		}
+
+		removed, added = removed[1:], added[1:]
+	}
+
+	for _, v := range removed {
+		err := z.removeRecord(change.Before.Name, change.Before.Type, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, v := range added {
+		err := z.addRecord(change.After.Name, change.After.Type, change.After.TTL, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *ZoneFileProvider) zonePath(ctx context.Context, zone string) (string, error) {
+	p.mutex.Lock()
+	path, found := p.files[zone]
+	p.mutex.Unlock()
+
+	if found {
+		return path, nil
+	}
+
+	_, err := p.ListZones(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if path, found = p.files[zone]; !found {
+		return "", fmt.Errorf("zone %s not found in %s", zone, p.cfg.Dir)
+	}
+
+	return path, nil
+}
+
+// diffChange returns the values removed and added by the change
+func diffChange(change *provider.Change) (removed []string, added []string) {
+	var before, after []string
+	if change.Before != nil {
+		before = change.Before.Values
+	}
+	if change.After != nil {
+		after = change.After.Values
+	}
+
+	for _, v := range before {
+		if !slices.Contains(after, v) {
+			removed = append(removed, v)
+		}
+	}
+
+	for _, v := range after {
+		if !slices.Contains(before, v) {
+			added = append(added, v)
+		}
+	}
+
+	return removed, added
+}
+
+type recordKey struct {
+	name   string
+	rrType uint16
+}
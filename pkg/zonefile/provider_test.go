@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package zonefile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/czerwonk/dns-drain/pkg/provider"
+)
+
+func writeTestZone(t *testing.T) (dir string, path string) {
+	t.Helper()
+
+	dir = t.TempDir()
+	path = filepath.Join(dir, "example.com.zone")
+	err := os.WriteFile(path, []byte(testZone), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir, path
+}
+
+// withSerial returns the test zone with the serial incremented once
+func withSerial(content string) string {
+	serial := nextSerial(2024010101, time.Now())
+	return strings.Replace(content, "2024010101", strconv.FormatUint(uint64(serial), 10), 1)
+}
+
+func TestDrainAndUndrainSingleValue(t *testing.T) {
+	dir, path := writeTestZone(t)
+	p := NewProvider(Config{Dir: dir})
+	ctx := context.Background()
+
+	drained := &provider.RecordSet{Name: "api.example.com.", Type: "A", TTL: 3600, Values: []string{"10.0.0.3"}}
+	replaced := drained.WithValues([]string{"10.0.0.9"})
+
+	err := p.ApplyChange(ctx, "example.com.", &provider.Change{Before: drained, After: replaced})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.ApplyChange(ctx, "example.com.", &provider.Change{Before: replaced, After: drained})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	z, err := loadZoneFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertLines(t, z, withSerial(testZone))
+}
+
+func TestApplyChanges(t *testing.T) {
+	www := &provider.RecordSet{Name: "www.example.com.", Type: "A", TTL: 3600, Values: []string{"10.0.0.1", "10.0.0.2"}}
+	api := &provider.RecordSet{Name: "api.example.com.", Type: "A", TTL: 3600, Values: []string{"10.0.0.3"}}
+	missing := &provider.RecordSet{Name: "mail.example.com.", Type: "A", TTL: 3600, Values: []string{"10.0.0.5"}}
+
+	tests := []struct {
+		name    string
+		changes []*provider.Change
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "serial incremented once",
+			changes: []*provider.Change{
+				{Before: www, After: www.WithValues([]string{"10.0.0.2"})},
+				{Before: api, After: api.WithValues([]string{"10.0.0.9"})},
+			},
+			want: withSerial(strings.NewReplacer(
+				"www IN A 10.0.0.1\n    IN A 10.0.0.2", "www    IN A 10.0.0.2",
+				"api IN A 10.0.0.3", "api IN A 10.0.0.9").Replace(testZone)),
+		},
+		{
+			name: "file untouched on error",
+			changes: []*provider.Change{
+				{Before: api, After: api.WithValues([]string{"10.0.0.9"})},
+				{Before: missing, After: missing.WithValues([]string{"10.0.0.6"})},
+			},
+			want:    testZone,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, path := writeTestZone(t)
+			p := NewProvider(Config{Dir: dir})
+
+			n, err := p.ApplyChanges(context.Background(), "example.com.", test.changes)
+			if test.wantErr != (err != nil) {
+				t.Fatalf("expected error=%v, got %v", test.wantErr, err)
+			}
+
+			if !test.wantErr && n != len(test.changes) {
+				t.Fatalf("expected %d changes applied, got %d", len(test.changes), n)
+			}
+
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != test.want {
+				t.Fatalf("unexpected zone file:\n%s\nexpected:\n%s", b, test.want)
+			}
+		})
+	}
+}
+
+func TestListZonesDefaultPattern(t *testing.T) {
+	dir, _ := writeTestZone(t)
+	err := os.WriteFile(filepath.Join(dir, "changelog.json"), []byte("[]"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zones, err := NewProvider(Config{Dir: dir}).ListZones(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(zones, []string{"example.com."}) {
+		t.Fatalf("unexpected zones %v", zones)
+	}
+}
@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package zonefile
+
+// token is a word in a zone file entry (comments, parentheses and quotes are handled by the scanner)
+type token struct {
+	line  int
+	start int
+	end   int
+	text  string
+}
+
+// scanLine splits a line into tokens. depth is the parenthesis depth at the beginning of the line,
+// the depth at the end of the line is returned.
+func scanLine(idx int, line string, depth int) ([]token, int) {
+	tokens := make([]token, 0)
+
+	start := -1
+	quoted := false
+	flush := func(end int) {
+		if start >= 0 {
+			tokens = append(tokens, token{line: idx, start: start, end: end, text: line[start:end]})
+			start = -1
+		}
+	}
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+
+		if quoted {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				quoted = false
+			}
+			continue
+		}
+
+		switch c {
+		case '\\':
+			if start < 0 {
+				start = i
+			}
+			i++
+		case '"':
+			if start < 0 {
+				start = i
+			}
+			quoted = true
+		case ';':
+			flush(i)
+			return tokens, depth
+		case '(':
+			flush(i)
+			depth++
+		case ')':
+			flush(i)
+			depth--
+		case ' ', '\t', '\r':
+			flush(i)
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+	}
+
+	flush(len(line))
+	return tokens, depth
+}
@@ -0,0 +1,394 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package zonefile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const defaultTTL = 3600
+
+// zoneFile is a line based representation of a BIND master file. Changes are applied to the lines
+// directly, so comments, ordering and directives of the file are preserved.
+type zoneFile struct {
+	path    string
+	origin  string
+	lines   []string
+	entries []*entry
+}
+
+// entry is a resource record in the zone file spanning one or more lines
+type entry struct {
+	first  int
+	last   int
+	tokens []token
+	rr     dns.RR
+	origin string
+
+	// owner is false if the owner is omitted and inherited from the previous entry
+	owner bool
+}
+
+func loadZoneFile(path string) (*zoneFile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	z := &zoneFile{
+		path:   path,
+		origin: originFromFileName(path),
+		lines:  strings.Split(string(b), "\n"),
+	}
+
+	err = z.parse()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return z, nil
+}
+
+// originFromFileName derives the origin from file names like example.com.zone, db.example.com or example.com
+func originFromFileName(path string) string {
+	name := filepath.Base(path)
+	name = strings.TrimSuffix(name, ".zone")
+	name = strings.TrimSuffix(name, ".db")
+	name = strings.TrimPrefix(name, "db.")
+
+	return dns.Fqdn(name)
+}
+
+func (z *zoneFile) parse() error {
+	z.entries = make([]*entry, 0)
+
+	origin := z.origin
+	ttlDirective := ""
+	prevOwner := ""
+
+	for i := 0; i < len(z.lines); i++ {
+		tokens, depth := scanLine(i, z.lines[i], 0)
+		if len(tokens) == 0 {
+			continue
+		}
+
+		first := i
+		for depth > 0 && i+1 < len(z.lines) {
+			i++
+			var t []token
+			t, depth = scanLine(i, z.lines[i], depth)
+			tokens = append(tokens, t...)
+		}
+
+		if strings.HasPrefix(tokens[0].text, "$") {
+			switch strings.ToUpper(tokens[0].text) {
+			case "$ORIGIN":
+				if len(tokens) < 2 {
+					return fmt.Errorf("line %d: $ORIGIN without domain", first+1)
+				}
+				origin = absoluteName(tokens[1].text, origin)
+			case "$TTL":
+				if len(tokens) < 2 {
+					return fmt.Errorf("line %d: $TTL without value", first+1)
+				}
+				ttlDirective = "$TTL " + tokens[1].text
+			case "$INCLUDE":
+				return fmt.Errorf("line %d: $INCLUDE is not supported", first+1)
+			}
+			continue
+		}
+
+		e := &entry{
+			first:  first,
+			last:   i,
+			tokens: tokens,
+			origin: origin,
+			owner:  tokens[0].start == 0 && tokens[0].line == first,
+		}
+
+		text := strings.Join(z.lines[first:i+1], "\n")
+		if !e.owner {
+			if prevOwner == "" {
+				return fmt.Errorf("line %d: record without owner", first+1)
+			}
+			text = prevOwner + " " + text
+		}
+
+		if ttlDirective != "" {
+			text = ttlDirective + "\n" + text
+		}
+
+		zp := dns.NewZoneParser(strings.NewReader(text), origin, z.path)
+		if len(z.entries) > 0 {
+			zp.SetDefaultTTL(z.entries[len(z.entries)-1].rr.Header().Ttl)
+		} else {
+			zp.SetDefaultTTL(defaultTTL)
+		}
+
+		rr, ok := zp.Next()
+		if !ok {
+			if err := zp.Err(); err != nil {
+				return fmt.Errorf("line %d: %w", first+1, err)
+			}
+			return fmt.Errorf("line %d: could not parse record", first+1)
+		}
+
+		e.rr = rr
+		prevOwner = rr.Header().Name
+		z.entries = append(z.entries, e)
+	}
+
+	return nil
+}
+
+func absoluteName(name, origin string) string {
+	if name == "@" {
+		return origin
+	}
+
+	if dns.IsFqdn(name) {
+		return name
+	}
+
+	return name + "." + origin
+}
+
+func (z *zoneFile) soa() (*entry, *dns.SOA) {
+	for _, e := range z.entries {
+		if soa, ok := e.rr.(*dns.SOA); ok {
+			return e, soa
+		}
+	}
+
+	return nil, nil
+}
+
+// name returns the name of the zone (owner of the SOA record)
+func (z *zoneFile) name() (string, error) {
+	_, soa := z.soa()
+	if soa == nil {
+		return "", fmt.Errorf("%s: no SOA record found", z.path)
+	}
+
+	return soa.Hdr.Name, nil
+}
+
+func (z *zoneFile) save() error {
+	info, err := os.Stat(z.path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(z.path), "."+filepath.Base(z.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(strings.Join(z.lines, "\n"))
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+
+	err = tmp.Sync()
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(tmp.Name(), info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), z.path)
+}
+
+// removeRecord removes the entry of the record from the file. If the following entry inherits the owner
+// of the removed one, the owner is written explicitly.
+func (z *zoneFile) removeRecord(name, rrType, value string) error {
+	for i, e := range z.entries {
+		if !matchesRecord(e.rr, name, rrType, value) {
+			continue
+		}
+
+		if e.owner && i+1 < len(z.entries) && !z.entries[i+1].owner {
+			next := z.entries[i+1]
+			z.lines[next.first] = e.tokens[0].text + z.lines[next.first]
+		}
+
+		z.lines = append(z.lines[:e.first], z.lines[e.last+1:]...)
+		return z.parse()
+	}
+
+	return fmt.Errorf("record %s %s %s not found in %s", name, rrType, value, z.path)
+}
+
+// addRecord adds a record after the last entry of the record set, or at the end of file if the record set does not exist
+func (z *zoneFile) addRecord(name, rrType string, ttl int64, value string) error {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+
+	pos := -1
+	origin := ""
+	for _, e := range z.entries {
+		if e.rr.Header().Name == name && dns.TypeToString[e.rr.Header().Rrtype] == rrType {
+			pos = e.last + 1
+			origin = e.origin
+		}
+	}
+
+	if pos < 0 {
+		pos = len(z.lines)
+		if pos > 0 && z.lines[pos-1] == "" {
+			pos--
+		}
+	}
+
+	line := fmt.Sprintf("%s\t%d\tIN\t%s\t%s", relativeName(name, origin), ttl, rrType, value)
+
+	rr, err := dns.NewRR(fmt.Sprintf("%s %d IN %s %s", name, ttl, rrType, value))
+	if err != nil {
+		return fmt.Errorf("could not parse %s %s %s: %w", name, rrType, value, err)
+	}
+	if rr == nil {
+		return fmt.Errorf("could not parse %s %s %s", name, rrType, value)
+	}
+
+	z.lines = append(z.lines[:pos], append([]string{line}, z.lines[pos:]...)...)
+	return z.parse()
+}
+
+// replaceRecord replaces the value of a record in place, keeping owner, TTL, class and comments of the entry.
+// Values spanning multiple lines are removed and added again.
+func (z *zoneFile) replaceRecord(name, rrType string, ttl int64, old, value string) error {
+	for _, e := range z.entries {
+		if !matchesRecord(e.rr, name, rrType, old) {
+			continue
+		}
+
+		rr, err := dns.NewRR(fmt.Sprintf("%s %d IN %s %s", name, e.rr.Header().Ttl, rrType, value))
+		if err != nil {
+			return fmt.Errorf("could not parse %s %s %s: %w", name, rrType, value, err)
+		}
+		if rr == nil {
+			return fmt.Errorf("could not parse %s %s %s", name, rrType, value)
+		}
+
+		idx := e.rdataIndex(rrType)
+		if idx < 0 || e.tokens[idx].line != e.tokens[len(e.tokens)-1].line {
+			break
+		}
+
+		first, last := e.tokens[idx], e.tokens[len(e.tokens)-1]
+		line := z.lines[first.line]
+		z.lines[first.line] = line[:first.start] + value + line[last.end:]
+
+		return z.parse()
+	}
+
+	err := z.removeRecord(name, rrType, old)
+	if err != nil {
+		return err
+	}
+
+	return z.addRecord(name, rrType, ttl, value)
+}
+
+// rdataIndex returns the index of the first token of the record data, or -1 if the type could not be located
+func (e *entry) rdataIndex(rrType string) int {
+	start := 0
+	if e.owner {
+		start = 1
+	}
+
+	// type is preceded by TTL and class at most
+	for i := start; i < len(e.tokens) && i <= start+2; i++ {
+		if strings.EqualFold(e.tokens[i].text, rrType) {
+			if i+1 < len(e.tokens) {
+				return i + 1
+			}
+			return -1
+		}
+	}
+
+	return -1
+}
+
+func relativeName(name, origin string) string {
+	if origin == "" {
+		return name
+	}
+
+	if name == origin {
+		return "@"
+	}
+
+	if strings.HasSuffix(name, "."+origin) {
+		return strings.TrimSuffix(name, "."+origin)
+	}
+
+	return name
+}
+
+func matchesRecord(rr dns.RR, name, rrType, value string) bool {
+	return rr.Header().Name == name && dns.TypeToString[rr.Header().Rrtype] == rrType && rdata(rr) == value
+}
+
+func rdata(rr dns.RR) string {
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
+
+// bumpSerial increments the serial of the SOA record. Date based serials (YYYYMMDDnn) are set to the current date if possible.
+func (z *zoneFile) bumpSerial(now time.Time) error {
+	e, soa := z.soa()
+	if soa == nil {
+		return fmt.Errorf("%s: no SOA record found", z.path)
+	}
+
+	tokens := e.tokens
+	idx := -1
+	for i, t := range tokens {
+		if strings.EqualFold(t.text, "SOA") {
+			idx = i + 3
+			break
+		}
+	}
+
+	if idx < 0 || idx >= len(tokens) || tokens[idx].text != strconv.FormatUint(uint64(soa.Serial), 10) {
+		return fmt.Errorf("%s: could not locate SOA serial", z.path)
+	}
+
+	t := tokens[idx]
+	line := z.lines[t.line]
+	z.lines[t.line] = line[:t.start] + strconv.FormatUint(uint64(nextSerial(soa.Serial, now)), 10) + line[t.end:]
+
+	return z.parse()
+}
+
+func nextSerial(serial uint32, now time.Time) uint32 {
+	next := serial + 1
+
+	date, _ := strconv.ParseUint(now.Format("20060102"), 10, 32)
+	if serial >= 1970010100 && serial < 2100010100 && uint32(date*100) > next {
+		return uint32(date * 100)
+	}
+
+	return next
+}
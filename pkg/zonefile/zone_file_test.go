@@ -0,0 +1,238 @@
+// SPDX-FileCopyrightText: (c) 2016 Daniel Czerwonk
+//
+// SPDX-License-Identifier: MIT
+
+package zonefile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testZone = `$TTL 1h
+$ORIGIN example.com.
+@ IN SOA ns1 hostmaster (
+        2024010101 ; serial
+        3600 900 604800 300 )
+  IN NS ns1
+; web servers
+www IN A 10.0.0.1
+    IN A 10.0.0.2
+api IN A 10.0.0.3
+`
+
+func loadTestZone(t *testing.T, content string) *zoneFile {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "example.com.zone")
+	err := os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	z, err := loadZoneFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return z
+}
+
+func assertLines(t *testing.T, z *zoneFile, want string) {
+	t.Helper()
+
+	got := strings.Join(z.lines, "\n")
+	if got != want {
+		t.Fatalf("unexpected zone file:\n%s\nexpected:\n%s", got, want)
+	}
+}
+
+func TestRemoveRecord(t *testing.T) {
+	tests := []struct {
+		name  string
+		owner string
+		value string
+		want  string
+	}{
+		{
+			name:  "owner written to following entry",
+			owner: "www.example.com.",
+			value: "10.0.0.1",
+			want:  strings.Replace(testZone, "www IN A 10.0.0.1\n    IN A 10.0.0.2", "www    IN A 10.0.0.2", 1),
+		},
+		{
+			name:  "entry inheriting owner",
+			owner: "www.example.com.",
+			value: "10.0.0.2",
+			want:  strings.Replace(testZone, "    IN A 10.0.0.2\n", "", 1),
+		},
+		{
+			name:  "single entry",
+			owner: "api.example.com.",
+			value: "10.0.0.3",
+			want:  strings.Replace(testZone, "api IN A 10.0.0.3\n", "", 1),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			z := loadTestZone(t, testZone)
+
+			err := z.removeRecord(test.owner, "A", test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertLines(t, z, test.want)
+		})
+	}
+}
+
+func TestRemoveRecordNotFound(t *testing.T) {
+	z := loadTestZone(t, testZone)
+
+	err := z.removeRecord("www.example.com.", "A", "10.0.0.9")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestAddRecord(t *testing.T) {
+	tests := []struct {
+		name  string
+		owner string
+		value string
+		want  string
+	}{
+		{
+			name:  "added after record set",
+			owner: "www.example.com.",
+			value: "10.0.0.4",
+			want:  strings.Replace(testZone, "    IN A 10.0.0.2\n", "    IN A 10.0.0.2\nwww\t300\tIN\tA\t10.0.0.4\n", 1),
+		},
+		{
+			name:  "new record set at end of file",
+			owner: "mail.example.com.",
+			value: "10.0.0.5",
+			want:  testZone[:len(testZone)-1] + "\nmail.example.com.\t300\tIN\tA\t10.0.0.5\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			z := loadTestZone(t, testZone)
+
+			err := z.addRecord(test.owner, "A", 300, test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertLines(t, z, test.want)
+		})
+	}
+}
+
+func TestReplaceRecord(t *testing.T) {
+	tests := []struct {
+		name  string
+		owner string
+		old   string
+		value string
+		want  string
+	}{
+		{
+			name:  "entry with owner",
+			owner: "www.example.com.",
+			old:   "10.0.0.1",
+			value: "10.0.0.9",
+			want:  strings.Replace(testZone, "www IN A 10.0.0.1", "www IN A 10.0.0.9", 1),
+		},
+		{
+			name:  "entry inheriting owner",
+			owner: "www.example.com.",
+			old:   "10.0.0.2",
+			value: "10.0.0.9",
+			want:  strings.Replace(testZone, "    IN A 10.0.0.2", "    IN A 10.0.0.9", 1),
+		},
+		{
+			name:  "single entry",
+			owner: "api.example.com.",
+			old:   "10.0.0.3",
+			value: "10.0.0.9",
+			want:  strings.Replace(testZone, "api IN A 10.0.0.3", "api IN A 10.0.0.9", 1),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			z := loadTestZone(t, testZone)
+
+			err := z.replaceRecord(test.owner, "A", 3600, test.old, test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertLines(t, z, test.want)
+		})
+	}
+}
+
+func TestBumpSerial(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	z := loadTestZone(t, testZone)
+	err := z.bumpSerial(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertLines(t, z, strings.Replace(testZone, "2024010101 ; serial", "2026101800 ; serial", 1))
+
+	_, soa := z.soa()
+	if soa.Serial != 2026101800 {
+		t.Fatalf("unexpected serial %d", soa.Serial)
+	}
+}
+
+func TestNextSerial(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		serial uint32
+		want   uint32
+	}{
+		{
+			name:   "date based serial of earlier day",
+			serial: 2024010101,
+			want:   2026101800,
+		},
+		{
+			name:   "date based serial of same day",
+			serial: 2026101805,
+			want:   2026101806,
+		},
+		{
+			name:   "date based serial in the future",
+			serial: 2027010100,
+			want:   2027010101,
+		},
+		{
+			name:   "counter",
+			serial: 42,
+			want:   43,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := nextSerial(test.serial, now)
+			if got != test.want {
+				t.Fatalf("expected %d, got %d", test.want, got)
+			}
+		})
+	}
+}